@@ -1,16 +1,21 @@
 package main
 
 import (
+	"bytes"
 	"fmt"
+	"maps"
 	"os"
-	"path/filepath"
+	"slices"
+	"strings"
 
 	"github.com/charmbracelet/huh"
-	"github.com/sergi/go-diff/diffmatchpatch"
 	"github.com/urfave/cli/v2"
 
+	"github.com/networkteam/shry/config"
 	"github.com/networkteam/shry/diff"
-	"github.com/networkteam/shry/template"
+	"github.com/networkteam/shry/install"
+	"github.com/networkteam/shry/merge"
+	"github.com/networkteam/shry/registry"
 	"github.com/networkteam/shry/ui"
 )
 
@@ -18,169 +23,571 @@ func componentAddCommand() *cli.Command {
 	return &cli.Command{
 		Name:      "add",
 		Usage:     "Add a component to the project",
-		ArgsUsage: "component-name",
+		ArgsUsage: "[component-name...]",
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:  "patch",
+				Usage: "Print the changes as a patch for git apply instead of writing files, existing files are overwritten",
+			},
+			&cli.StringFlag{
+				Name:  "packages",
+				Usage: "How to handle packages required by components that are missing in the project manifests: ask, update, print or skip",
+				Value: packagesAsk,
+			},
+			&cli.StringSliceFlag{
+				Name:  "variant",
+				Usage: "Variant of the component, use `component=variant` when adding several components",
+			},
+			noHooksFlag(),
+		},
 		Action: func(c *cli.Context) error {
-			projectConfig, reg, err := loadProjectAndRegistry(c)
+			projectConfig, globalConfig, reg, err := loadProject(c)
 			if err != nil {
 				return err
 			}
 
-			componentName := c.Args().First()
+			packagesMode := c.String("packages")
+			if !slices.Contains([]string{packagesAsk, packagesUpdate, packagesPrint, packagesSkip}, packagesMode) {
+				return fmt.Errorf("invalid --packages value %q, use ask, update, print or skip", packagesMode)
+			}
+
+			componentNames := c.Args().Slice()
+			selectedVariants := make(map[string]string)
+
+			available, err := reg.ScanComponents()
+			if err != nil {
+				return fmt.Errorf("scanning components: %w", err)
+			}
 
 			// If no component name provided, show interactive selector
-			if componentName == "" {
-				// Scan components to show in selector
-				components, err := reg.ScanComponents()
-				if err != nil {
-					return fmt.Errorf("scanning components: %w", err)
+			if len(componentNames) == 0 {
+				// The selector would be written into the patch
+				if c.Bool("patch") {
+					return fmt.Errorf("component names are required with --patch")
 				}
 
-				selectedName, err := ui.ShowComponentSelector(components, projectConfig.Platform)
+				selectedNames, err := ui.ShowComponentSelector(available, projectConfig.Platform, projectConfig.Variables)
 				if err != nil {
 					return err
 				}
 
-				if selectedName == "" {
+				if len(selectedNames) == 0 {
 					return nil
 				}
 
-				componentName = selectedName
+				componentNames = selectedNames
+
+				// Ask for variants of the selected components, the variant added before is preselected
+				if len(c.StringSlice("variant")) == 0 {
+					for _, name := range componentNames {
+						component := available[projectConfig.Platform][name]
+						if component == nil || len(component.Variants) == 0 {
+							continue
+						}
+						variant, err := selectVariant(component, recordedVariant(projectConfig, component))
+						if err != nil {
+							return err
+						}
+						selectedVariants[name] = variant
+					}
+				}
 			}
 
-			// Resolve component and verify variables
-			component, err := reg.ResolveComponent(projectConfig.Platform, componentName)
+			// Variants added before apply again, unless a variant is given
+			variants, err := parseVariants(c.StringSlice("variant"), componentNames)
 			if err != nil {
 				return err
 			}
+			maps.Copy(variants, selectedVariants)
+			for name := range projectConfig.Variants {
+				if _, exists := variants[name]; exists {
+					continue
+				}
+				if component := available[projectConfig.Platform][name]; component != nil {
+					variants[name] = recordedVariant(projectConfig, component)
+				}
+			}
 
-			// Resolve files and verify variables
-			resolvedFiles, err := component.ResolveFiles(projectConfig.Variables)
+			// Resolve components with their dependencies
+			components, err := reg.ResolveComponentsWithVariants(projectConfig.Platform, componentNames, variants)
 			if err != nil {
 				return err
 			}
 
-			// Add the component
-			fmt.Printf("Adding component %s...\n", componentName)
-			for _, file := range resolvedFiles {
+			// Read all files and check existing destinations before writing anything
+			plan, err := install.NewPlan(reg, projectConfig, components)
+			if err != nil {
+				return err
+			}
 
-				// Read source file
-				srcPath := filepath.Join(component.Path, file.Src)
-				srcContent, err := reg.ReadFile(srcPath)
+			// Existing files are overwritten or merged in the patch, the review decides about the changes
+			if c.Bool("patch") {
+				for _, change := range plan.Conflicts() {
+					if change.Merged != nil {
+						setMerged(change, change.Merged)
+						continue
+					}
+					change.Action = install.ActionOverwrite
+				}
+				manifests, err := patchPackages(c, plan, projectConfig, components, packagesMode)
 				if err != nil {
-					return fmt.Errorf("reading source file %s: %w", srcPath, err)
+					return err
 				}
+				return plan.Patch(c.App.Writer, manifests...)
+			}
 
-				// Substitute variables in content
-				newContent, err := template.Resolve(string(srcContent), projectConfig.Variables)
-				if err != nil {
-					return fmt.Errorf("resolving variables in content: %w", err)
+			// Decide how to handle all existing files first
+			for _, change := range plan.Conflicts() {
+				if err := decideConflict(change, globalConfig); err != nil {
+					return err
 				}
+			}
 
-				// Check for existing files
-				dstPath := filepath.Join(projectConfig.ProjectDir, file.Dst)
-				if _, err := os.Stat(dstPath); err == nil {
-					existingContent, err := os.ReadFile(dstPath)
-					if err != nil {
-						return fmt.Errorf("reading existing file: %w", err)
-					}
+			if err := plan.Apply(); err != nil {
+				return err
+			}
 
-					dmp := diffmatchpatch.New()
-					diffs := dmp.DiffMain(string(existingContent), newContent, false)
+			printPlan(plan)
 
-					// Only show if there are actual changes
-					hasChanges := false
-					for _, diff := range diffs {
-						if diff.Type != diffmatchpatch.DiffEqual {
-							hasChanges = true
-							break
-						}
-					}
+			// Record the variants of the added components for later updates
+			changed := false
+			for _, name := range componentNames {
+				if projectConfig.SetVariant(name, variants[name]) {
+					changed = true
+				}
+			}
+			if changed {
+				if err := projectConfig.Save(); err != nil {
+					return err
+				}
+			}
 
-					if !hasChanges {
-						fmt.Printf("  Unchanged %s\n", file.Dst)
-						continue
-					}
+			// Packages are checked for all components, skipped files might still need them
+			if err := addPackages(projectConfig, components, packagesMode); err != nil {
+				return err
+			}
 
-					var choice string
-					err = huh.NewForm(
-						huh.NewGroup(
-							huh.NewSelect[string]().
-								Title(fmt.Sprintf("File already exists: %s", file.Dst)).
-								Options(
-									huh.NewOption("Skip", "skip"),
-									huh.NewOption("Overwrite", "overwrite"),
-									huh.NewOption("Diff", "diff"),
-								).
-								Value(&choice),
-						),
-					).Run()
-					if err != nil {
-						return err
-					}
+			// Hooks only run for components with written files
+			hooks, err := install.PostAddHooks(plan.WrittenComponents(), projectConfig.Variables)
+			if err != nil {
+				return err
+			}
+			return runHooks(c, globalConfig, projectConfig, reg, "postAdd", hooks)
+		},
+	}
+}
 
-					if choice == "skip" {
-						fmt.Printf("  Skipped %s\n", file.Dst)
-						continue
-					}
+const (
+	// packagesAsk asks whether to update the manifests or print install commands for missing packages
+	packagesAsk = "ask"
+	// packagesUpdate adds missing packages to the manifests
+	packagesUpdate = "update"
+	// packagesPrint prints the install commands of missing packages
+	packagesPrint = "print"
+	// packagesSkip only reports missing packages
+	packagesSkip = "skip"
+)
 
-					if choice == "diff" {
-						lineText1, lineText2, lineArray := dmp.DiffLinesToChars(string(existingContent), newContent)
-						diffs := dmp.DiffMain(lineText1, lineText2, false)
-						diffs = dmp.DiffCharsToLines(diffs, lineArray)
-
-						diff.PrettyPrint(diffs)
-
-						var choice string
-						err = huh.NewForm(
-							huh.NewGroup(
-								huh.NewSelect[string]().
-									Title(fmt.Sprintf("File already exists: %s", file.Dst)).
-									Options(
-										huh.NewOption("Skip", "skip"),
-										huh.NewOption("Overwrite", "overwrite"),
-									).
-									Value(&choice),
-							),
-						).Run()
-						if err != nil {
-							return err
-						}
+// addPackages reports packages of the components missing in the project manifests and handles them by mode
+func addPackages(projectConfig *config.ProjectConfig, components []*config.Component, mode string) error {
+	missing, err := install.MissingPackages(projectConfig.ProjectDir, install.RequiredPackages(components))
+	if err != nil {
+		return err
+	}
+	if len(missing) == 0 {
+		return nil
+	}
 
-						if choice == "overwrite" {
-							// Write destination file
-							if err := os.WriteFile(dstPath, []byte(newContent), 0644); err != nil {
-								return fmt.Errorf("writing destination file: %w", err)
-							}
+	fmt.Println("\nMissing packages:")
+	for _, pkg := range missing {
+		fmt.Printf("  %s %s %s\n", ui.HelpStyle.Render(string(pkg.Ecosystem)+":"), pkg, ui.HelpStyle.Render("("+pkg.Component.Name+")"))
+	}
 
-							fmt.Printf("  Overwrite %s\n", file.Dst)
+	if mode == packagesAsk {
+		err := huh.NewForm(
+			huh.NewGroup(
+				huh.NewSelect[string]().
+					Title("Install missing packages?").
+					Options(
+						huh.NewOption("Add to manifests", packagesUpdate),
+						huh.NewOption("Print install commands", packagesPrint),
+						huh.NewOption("Skip", packagesSkip),
+					).
+					Value(&mode),
+			),
+		).Run()
+		if err != nil {
+			return err
+		}
+	}
 
-							continue
-						}
+	switch mode {
+	case packagesUpdate:
+		updates, notUpdated, err := install.ManifestUpdates(projectConfig.ProjectDir, missing)
+		if err != nil {
+			return err
+		}
+		for _, update := range updates {
+			if err := update.Apply(projectConfig.ProjectDir); err != nil {
+				return err
+			}
+			if update.Existing == nil {
+				fmt.Printf("  Created %s\n", update.Path)
+			} else {
+				fmt.Printf("  Updated %s\n", update.Path)
+			}
+		}
+		if len(updates) > 0 {
+			fmt.Println("Run your package manager to install the added packages.")
+		}
+		printInstallCommands(projectConfig.ProjectDir, notUpdated)
+	case packagesPrint:
+		printInstallCommands(projectConfig.ProjectDir, missing)
+	}
+	return nil
+}
 
-						if choice == "skip" {
-							fmt.Printf("  Skipped %s\n", file.Dst)
-							continue
-						}
-					}
+// printInstallCommands prints the commands to install the packages
+func printInstallCommands(projectDir string, packages []install.Package) {
+	commands := install.InstallCommands(projectDir, packages)
+	if len(commands) == 0 {
+		return
+	}
+	fmt.Println("Install them with:")
+	for _, command := range commands {
+		fmt.Printf("  %s\n", command)
+	}
+}
 
-					// FIXME Check if this is reachable at all
-					return fmt.Errorf("destination file already exists: %s", dstPath)
-				}
+// patchPackages returns manifest updates for missing packages to include in the patch with --packages=update,
+// otherwise the install commands are printed to stderr to keep the patch applicable
+func patchPackages(c *cli.Context, plan *install.Plan, projectConfig *config.ProjectConfig, components []*config.Component, mode string) ([]*install.ManifestUpdate, error) {
+	missing, err := install.MissingPackages(projectConfig.ProjectDir, install.RequiredPackages(components))
+	if err != nil {
+		return nil, err
+	}
+	if len(missing) == 0 || mode == packagesSkip {
+		return nil, nil
+	}
 
-				// Create destination directory if needed
-				if err := os.MkdirAll(filepath.Dir(dstPath), 0755); err != nil {
-					return fmt.Errorf("creating destination directory: %w", err)
-				}
+	// Manifests are updated on top of the plan, so a manifest written by a component has a single diff
+	var updates []*install.ManifestUpdate
+	if mode == packagesUpdate {
+		updates, missing, err = plan.ManifestUpdates(missing)
+		if err != nil {
+			return nil, err
+		}
+	}
 
-				// Write destination file
-				if err := os.WriteFile(dstPath, []byte(newContent), 0644); err != nil {
-					return fmt.Errorf("writing destination file: %w", err)
-				}
+	for _, command := range install.InstallCommands(projectConfig.ProjectDir, missing) {
+		fmt.Fprintf(c.App.ErrWriter, "Missing packages, install them with: %s\n", command)
+	}
+	return updates, nil
+}
+
+// parseVariants parses `component=variant` values of the --variant flag.
+// A value without a component name is the variant of the only component.
+func parseVariants(values []string, componentNames []string) (map[string]string, error) {
+	variants := make(map[string]string)
+	for _, value := range values {
+		name, variant, found := strings.Cut(value, "=")
+		if !found {
+			if len(componentNames) != 1 {
+				return nil, fmt.Errorf("use --variant component=variant to select a variant of one of several components")
+			}
+			name, variant = componentNames[0], value
+		}
+		if !slices.Contains(componentNames, name) {
+			return nil, fmt.Errorf("variant %s is given for component %s, which is not added", variant, name)
+		}
+		variants[name] = variant
+	}
+	return variants, nil
+}
+
+// recordedVariant returns the variant recorded for the component in the project config.
+// If the registry no longer offers the variant, the base component is used with a warning.
+func recordedVariant(projectConfig *config.ProjectConfig, component *config.Component) string {
+	variant := projectConfig.Variants[component.Name]
+	if variant == "" {
+		return ""
+	}
+	if _, exists := component.Variants[variant]; !exists {
+		fmt.Fprintf(os.Stderr, "%s variant %s of component %s recorded in .shry.yaml not found, using the base component\n", ui.WarningStyle.Render("Warning:"), variant, component.Name)
+		return ""
+	}
+	return variant
+}
+
+// selectVariant asks for a variant of the component, the base component has the empty variant
+func selectVariant(component *config.Component, current string) (string, error) {
+	options := []huh.Option[string]{huh.NewOption("Default", "")}
+	for _, name := range component.VariantNames() {
+		label := name
+		if title := component.Variants[name].Title; title != "" {
+			label = fmt.Sprintf("%s (%s)", name, title)
+		}
+		options = append(options, huh.NewOption(label, name))
+	}
+
+	variant := current
+	err := huh.NewForm(
+		huh.NewGroup(
+			huh.NewSelect[string]().
+				Title(fmt.Sprintf("Variant of %s", component.Name)).
+				Options(options...).
+				Value(&variant),
+		),
+	).Run()
+	if err != nil {
+		return "", err
+	}
+	return variant, nil
+}
+
+// noHooksFlag disables running component hooks
+func noHooksFlag() cli.Flag {
+	return &cli.BoolFlag{
+		Name:  "no-hooks",
+		Usage: "Do not run hooks of components",
+	}
+}
+
+// runHooks shows the hooks and runs them if the registry of the project is trusted.
+// The user is asked to trust the registry if there is no remembered decision.
+func runHooks(c *cli.Context, globalConfig *config.GlobalConfig, projectConfig *config.ProjectConfig, reg *registry.Registry, kind string, hooks []install.Hook) error {
+	if len(hooks) == 0 {
+		return nil
+	}
+
+	fmt.Printf("\n%s hooks:\n", kind)
+	for _, hook := range hooks {
+		fmt.Printf("  %s %s\n", ui.HelpStyle.Render(hook.Component.Name+":"), hook.Command)
+	}
+
+	if c.Bool("no-hooks") {
+		fmt.Println("Skipped hooks (--no-hooks)")
+		return nil
+	}
+
+	// The decision is remembered for the registry like it is stored by `shry registry add`
+	registryLocation, _ := registry.SplitRef(projectConfig.Registry)
+	if !reg.IsGit() {
+		registryLocation = reg.Name
+	}
+	run, err := decideHooksTrust(globalConfig, registryLocation)
+	if err != nil {
+		return err
+	}
+	if !run {
+		fmt.Println("Skipped hooks")
+		return nil
+	}
+
+	return install.RunHooks(projectConfig.ProjectDir, hooks)
+}
+
+// hooksChoice is an answer to the question whether hooks of a registry are run
+type hooksChoice int
+
+const (
+	hooksRunOnce hooksChoice = iota
+	hooksAlways
+	hooksSkipOnce
+	hooksNever
+)
+
+// decideHooksTrust returns whether hooks of the registry are run, asking the user if there is no remembered decision
+func decideHooksTrust(globalConfig *config.GlobalConfig, registryLocation string) (bool, error) {
+	switch globalConfig.HooksTrust(registryLocation) {
+	case config.HooksTrusted:
+		return true, nil
+	case config.HooksDenied:
+		fmt.Printf("Hooks of registry %s are denied, allow them with `shry config trust-hooks %s`\n", registryLocation, registryLocation)
+		return false, nil
+	}
+
+	var choice hooksChoice
+	err := huh.NewForm(
+		huh.NewGroup(
+			huh.NewSelect[hooksChoice]().
+				Title(fmt.Sprintf("Run hooks of registry %s?", registryLocation)).
+				Description("Hooks run the shell commands above in your project.").
+				Options(
+					huh.NewOption("Run this time", hooksRunOnce),
+					huh.NewOption("Always run hooks of this registry", hooksAlways),
+					huh.NewOption("Skip this time", hooksSkipOnce),
+					huh.NewOption("Never run hooks of this registry", hooksNever),
+				).
+				Value(&choice),
+		),
+	).Run()
+	if err != nil {
+		return false, err
+	}
+
+	// Remember the decision for the registry
+	if choice == hooksAlways || choice == hooksNever {
+		trust := config.HooksTrusted
+		if choice == hooksNever {
+			trust = config.HooksDenied
+		}
+		globalConfig.SetHooksTrust(registryLocation, trust)
+		if err := globalConfig.Save(); err != nil {
+			return false, fmt.Errorf("saving configuration: %w", err)
+		}
+	}
+
+	return choice == hooksRunOnce || choice == hooksAlways, nil
+}
+
+// decideConflict asks the user whether to overwrite an existing file with other content.
+// Files with a merge strategy default to the structured merge, external diff and merge tools are offered if configured.
+func decideConflict(change *install.Change, globalConfig *config.GlobalConfig) error {
+	diffTool := globalConfig.DiffToolCommand()
+	mergeTool := globalConfig.MergeToolCommand()
 
-				fmt.Printf("  Added %s\n", file.Dst)
+	description := fmt.Sprintf("Component %s", change.Component.Name)
+	if change.Merged != nil {
+		description += "\n\n" + mergeChangesDescription(change.MergeChanges)
+	}
+
+	for {
+		var options []huh.Option[install.Action]
+		if change.Merged != nil {
+			options = append(options, huh.NewOption(fmt.Sprintf("Merge structured (%d changes)", len(change.MergeChanges)), actionMergeStructured))
+		}
+		options = append(options,
+			huh.NewOption("Skip", install.ActionSkip),
+			huh.NewOption("Overwrite", install.ActionOverwrite),
+			huh.NewOption("Diff", actionDiff),
+			huh.NewOption("Merge changes", actionMerge),
+		)
+		if diffTool != "" {
+			options = append(options, huh.NewOption("Open in diff tool", actionDiffTool))
+		}
+		if mergeTool != "" {
+			options = append(options, huh.NewOption("Merge in merge tool", actionMergeTool))
+		}
+
+		var choice install.Action
+		err := huh.NewForm(
+			huh.NewGroup(
+				huh.NewSelect[install.Action]().
+					Title(fmt.Sprintf("File already exists: %s", change.Dst)).
+					Description(description).
+					Options(options...).
+					Value(&choice),
+			),
+		).Run()
+		if err != nil {
+			return err
+		}
+
+		switch choice {
+		case actionMergeStructured:
+			setMerged(change, change.Merged)
+			return nil
+		case actionDiff:
+			diff.PrettyPrint(diff.LineDiff(string(change.Existing), string(change.Content)))
+		case actionMerge:
+			merged, ok, err := diff.ResolveHunks(fmt.Sprintf("Merge changes: %s", change.Dst), string(change.Existing), string(change.Content))
+			if err != nil {
+				return err
+			}
+			// Ask again if the merge was cancelled
+			if !ok {
+				continue
 			}
 
+			setMerged(change, []byte(merged))
 			return nil
-		},
+		case actionDiffTool:
+			if err := diff.RunDiffTool(diffTool, change.Dst, change.Existing, change.Content); err != nil {
+				fmt.Println(ui.ErrorStyle.Render(fmt.Sprintf("Diff tool failed: %v", err)))
+			}
+		case actionMergeTool:
+			merged, err := diff.RunMergeTool(mergeTool, change.Dst, change.Existing, change.Content)
+			// Ask again if the merge tool failed or was aborted
+			if err != nil {
+				fmt.Println(ui.ErrorStyle.Render(fmt.Sprintf("Merge tool failed: %v", err)))
+				continue
+			}
+
+			setMerged(change, merged)
+			return nil
+		default:
+			change.Action = choice
+			return nil
+		}
+	}
+}
+
+const (
+	// actionDiff is a prompt option to show the diff before deciding
+	actionDiff install.Action = -1
+	// actionMerge is a prompt option to step through the changes and take or keep each one
+	actionMerge install.Action = -2
+	// actionDiffTool is a prompt option to compare the files in the external diff tool
+	actionDiffTool install.Action = -3
+	// actionMergeTool is a prompt option to merge the files in the external merge tool
+	actionMergeTool install.Action = -4
+	// actionMergeStructured is a prompt option to write the result of the merge strategy of the file
+	actionMergeStructured install.Action = -5
+)
+
+// maxMergeChangesShown limits the semantic changes listed in the conflict prompt
+const maxMergeChangesShown = 15
+
+// mergeChangesDescription lists the semantic changes of a structured merge
+func mergeChangesDescription(changes []merge.Change) string {
+	var lines []string
+	for i, change := range changes {
+		if i == maxMergeChangesShown {
+			lines = append(lines, fmt.Sprintf("… and %d more", len(changes)-maxMergeChangesShown))
+			break
+		}
+		lines = append(lines, change.String())
+	}
+	return strings.Join(lines, "\n")
+}
+
+// setMerged writes the merged content, or keeps the existing file if nothing was merged
+func setMerged(change *install.Change, merged []byte) {
+	change.Action = install.ActionSkip
+	if !bytes.Equal(merged, change.Existing) {
+		change.Content = merged
+		change.Action = install.ActionMerge
+	}
+}
+
+// printPlan prints what happened to the files of each component
+func printPlan(plan *install.Plan) {
+	for _, component := range plan.Components {
+		if component.Variant != "" {
+			fmt.Printf("Adding component %s (variant %s)...\n", component.Name, component.Variant)
+		} else {
+			fmt.Printf("Adding component %s...\n", component.Name)
+		}
+		for _, change := range plan.Changes {
+			if change.Component != component {
+				continue
+			}
+
+			switch change.Action {
+			case install.ActionCreate:
+				fmt.Printf("  Added %s\n", change.Dst)
+			case install.ActionOverwrite:
+				fmt.Printf("  Overwrite %s\n", change.Dst)
+			case install.ActionMerge:
+				fmt.Printf("  Merged %s\n", change.Dst)
+			case install.ActionInsert:
+				fmt.Printf("  Inserted snippet into %s\n", change.Dst)
+			case install.ActionSkip:
+				fmt.Printf("  Skipped %s\n", change.Dst)
+			case install.ActionUnchanged:
+				fmt.Printf("  Unchanged %s\n", change.Dst)
+			}
+		}
 	}
 }
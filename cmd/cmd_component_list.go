@@ -2,15 +2,26 @@ package main
 
 import (
 	"fmt"
+	"os"
 
 	"github.com/urfave/cli/v2"
+
+	"github.com/networkteam/shry/ui"
 )
 
 func componentLsCommand() *cli.Command {
 	return &cli.Command{
 		Name:  "ls",
 		Usage: "List available components from the registry",
+		Flags: []cli.Flag{
+			ui.OutputFlag(),
+		},
 		Action: func(c *cli.Context) error {
+			format, err := ui.GetOutputFormat(c)
+			if err != nil {
+				return err
+			}
+
 			projectConfig, reg, err := loadProjectAndRegistry(c)
 			if err != nil {
 				return err
@@ -22,19 +33,39 @@ func componentLsCommand() *cli.Command {
 				return fmt.Errorf("scanning components: %w", err)
 			}
 
-			// Print components
-			fmt.Printf("Available components for platform %s:\n\n", projectConfig.Platform)
-			platformComponents, exists := components[projectConfig.Platform]
-			if !exists {
+			sorted := ui.SortComponents(components[projectConfig.Platform])
+
+			if format != ui.OutputText {
+				infos := make([]ui.ComponentInfo, 0, len(sorted))
+				for _, component := range sorted {
+					infos = append(infos, ui.NewComponentInfo(component))
+				}
+				return ui.WriteStructured(os.Stdout, format, infos)
+			}
+
+			// Print components grouped by category
+			if len(sorted) == 0 {
 				fmt.Printf("No components found for platform %s\n", projectConfig.Platform)
 				return nil
 			}
-			for name, component := range platformComponents {
-				fmt.Printf("%s\n", name)
-				if component.Description != "" {
-					fmt.Printf("  %s\n", component.Description)
+			fmt.Printf("Available components for platform %s:\n", projectConfig.Platform)
+			for _, group := range ui.GroupComponents(sorted) {
+				category := group.Category
+				if category == "" {
+					category = "Uncategorized"
+				}
+				fmt.Printf("\n%s\n", ui.TitleStyle.Render(category))
+
+				for _, component := range group.Components {
+					fmt.Printf("  %s", component.Name)
+					if component.Title != "" {
+						fmt.Printf(" %s", ui.HelpStyle.Render(component.Title))
+					}
+					fmt.Println()
+					if component.Description != "" {
+						fmt.Printf("    %s\n", component.Description)
+					}
 				}
-				fmt.Println()
 			}
 
 			return nil
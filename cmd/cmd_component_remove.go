@@ -0,0 +1,123 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/networkteam/shry/config"
+	"github.com/networkteam/shry/install"
+	"github.com/networkteam/shry/ui"
+)
+
+func componentRemoveCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "remove",
+		Aliases:   []string{"rm"},
+		Usage:     "Remove the files of components from the project",
+		ArgsUsage: "component-name...",
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:    "yes",
+				Aliases: []string{"y"},
+				Usage:   "Remove without asking for confirmation",
+			},
+			noHooksFlag(),
+		},
+		Action: func(c *cli.Context) error {
+			componentNames := c.Args().Slice()
+			if len(componentNames) == 0 {
+				return fmt.Errorf("component name is required")
+			}
+
+			projectConfig, globalConfig, reg, err := loadProject(c)
+			if err != nil {
+				return err
+			}
+
+			components, err := reg.ScanComponents()
+			if err != nil {
+				return fmt.Errorf("scanning components: %w", err)
+			}
+
+			// Dependencies are kept, they might be used by other components.
+			// The files of the variant that was added are removed.
+			var toRemove []*config.Component
+			for _, name := range componentNames {
+				component, exists := components[projectConfig.Platform][name]
+				if !exists {
+					return fmt.Errorf("component %s not found for platform %s", name, projectConfig.Platform)
+				}
+				component, err := component.WithVariant(recordedVariant(projectConfig, component))
+				if err != nil {
+					return err
+				}
+				toRemove = append(toRemove, component)
+			}
+
+			removal, err := install.NewRemoval(projectConfig, toRemove)
+			if err != nil {
+				return err
+			}
+			if len(removal.Files) == 0 && len(removal.Snippets) == 0 {
+				fmt.Println("No files of the components found in the project")
+				return nil
+			}
+
+			if !c.Bool("yes") {
+				lines := slices.Clone(removal.Files)
+				for _, snippet := range removal.Snippets {
+					lines = append(lines, fmt.Sprintf("%s (snippet)", snippet.Dst))
+				}
+
+				confirmOptions := ui.NewConfirmation(fmt.Sprintf("Remove %d files of %s?", len(lines), strings.Join(componentNames, ", "))).
+					WithDescription(strings.Join(lines, "\n")).
+					WithYesText("Remove").
+					WithNoText("Cancel")
+
+				confirmed, err := ui.ShowConfirmation(confirmOptions)
+				if err != nil {
+					return err
+				}
+				if !confirmed {
+					fmt.Println("Component removal cancelled.")
+					return nil
+				}
+			}
+
+			// Hooks run before files are removed, a failing hook keeps all files
+			hooks, err := install.PreRemoveHooks(removal.Components, projectConfig.Variables)
+			if err != nil {
+				return err
+			}
+			if err := runHooks(c, globalConfig, projectConfig, reg, "preRemove", hooks); err != nil {
+				return err
+			}
+
+			if err := removal.Apply(); err != nil {
+				return err
+			}
+
+			for _, snippet := range removal.Snippets {
+				fmt.Printf("Removed snippet from %s\n", snippet.Dst)
+			}
+			for _, file := range removal.Files {
+				fmt.Printf("Removed %s\n", file)
+			}
+
+			// Forget the variants of the removed components
+			changed := false
+			for _, name := range componentNames {
+				if projectConfig.SetVariant(name, "") {
+					changed = true
+				}
+			}
+			if changed {
+				return projectConfig.Save()
+			}
+			return nil
+		},
+	}
+}
@@ -0,0 +1,166 @@
+package main
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/networkteam/shry/config"
+	"github.com/networkteam/shry/registry"
+	"github.com/networkteam/shry/search"
+	"github.com/networkteam/shry/ui"
+)
+
+// searchResult is a component matching a search query
+type searchResult struct {
+	Registry         string `json:"registry" yaml:"registry"`
+	ui.ComponentInfo `yaml:",inline"`
+	Score            int `json:"score" yaml:"score"`
+}
+
+func componentSearchCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "search",
+		Usage:     "Search components by name, title, description, category and tags",
+		ArgsUsage: "query",
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:  "all",
+				Usage: "Search all configured registries and platforms instead of the project registry",
+			},
+			ui.OutputFlag(),
+		},
+		Action: func(c *cli.Context) error {
+			format, err := ui.GetOutputFormat(c)
+			if err != nil {
+				return err
+			}
+
+			query := strings.Join(c.Args().Slice(), " ")
+			if query == "" {
+				return fmt.Errorf("search query is required")
+			}
+
+			var results []searchResult
+			if c.Bool("all") {
+				results, err = searchAllRegistries(c, query)
+			} else {
+				results, err = searchProjectRegistry(c, query)
+			}
+			if err != nil {
+				return err
+			}
+
+			if format != ui.OutputText {
+				if results == nil {
+					results = []searchResult{}
+				}
+				return ui.WriteStructured(os.Stdout, format, results)
+			}
+
+			if len(results) == 0 {
+				fmt.Printf("No components found for %q\n", query)
+				return nil
+			}
+			for _, result := range results {
+				fmt.Printf("%s", result.Name)
+				if result.Title != "" {
+					fmt.Printf(" %s", ui.HelpStyle.Render(result.Title))
+				}
+				if c.Bool("all") {
+					fmt.Printf(" %s", ui.HelpStyle.Render(fmt.Sprintf("(%s, %s)", result.Platform, result.Registry)))
+				}
+				fmt.Println()
+				if result.Description != "" {
+					fmt.Printf("  %s\n", result.Description)
+				}
+			}
+
+			return nil
+		},
+	}
+}
+
+// searchProjectRegistry searches the components of the project platform in the project registry
+func searchProjectRegistry(c *cli.Context, query string) ([]searchResult, error) {
+	projectConfig, reg, err := loadProjectAndRegistry(c)
+	if err != nil {
+		return nil, err
+	}
+
+	components, err := reg.ScanComponents()
+	if err != nil {
+		return nil, fmt.Errorf("scanning components: %w", err)
+	}
+
+	return searchComponents(projectConfig.Registry, query, components[projectConfig.Platform]), nil
+}
+
+// searchAllRegistries searches the components of all platforms in all configured registries.
+// Registries that cannot be loaded are skipped with a warning.
+func searchAllRegistries(c *cli.Context, query string) ([]searchResult, error) {
+	globalConfig, err := config.LoadGlobalConfig(c.String("global-config"))
+	if err != nil {
+		return nil, err
+	}
+
+	cache, err := registry.NewCache(c.String("cache-dir"), globalConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cache: %w", err)
+	}
+	cache.Verbose = c.Bool("verbose")
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		return nil, fmt.Errorf("getting current directory: %w", err)
+	}
+
+	locations, err := globalConfig.RegistryLocations()
+	if err != nil {
+		return nil, fmt.Errorf("listing registries: %w", err)
+	}
+
+	var results []searchResult
+	for _, location := range locations {
+		reg, err := cache.GetRegistry(location, "", cwd)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s skipping registry %s: %v\n", ui.WarningStyle.Render("Warning:"), location, err)
+			continue
+		}
+
+		components, err := reg.ScanComponents()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s skipping registry %s: %v\n", ui.WarningStyle.Render("Warning:"), location, err)
+			continue
+		}
+
+		for _, platform := range slices.Sorted(maps.Keys(components)) {
+			results = append(results, searchComponents(location, query, components[platform])...)
+		}
+	}
+
+	// Merge the results of all registries by score
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Score > results[j].Score
+	})
+
+	return results, nil
+}
+
+// searchComponents ranks the components of a registry for the query
+func searchComponents(location string, query string, components map[string]*config.Component) []searchResult {
+	var results []searchResult
+	for _, match := range search.Rank(query, ui.SortComponents(components)) {
+		results = append(results, searchResult{
+			Registry:      location,
+			ComponentInfo: ui.NewComponentInfo(match.Component),
+			Score:         match.Score,
+		})
+	}
+	return results
+}
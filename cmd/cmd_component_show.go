@@ -0,0 +1,406 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/glamour"
+	"github.com/urfave/cli/v2"
+
+	"github.com/networkteam/shry/config"
+	"github.com/networkteam/shry/install"
+	"github.com/networkteam/shry/registry"
+	"github.com/networkteam/shry/template"
+	"github.com/networkteam/shry/ui"
+)
+
+// componentReadmeFile is the name of an optional README bundled with a component
+const componentReadmeFile = "README.md"
+
+// componentDetails is everything shown about a component
+type componentDetails struct {
+	Name         string              `json:"name" yaml:"name"`
+	Title        string              `json:"title,omitempty" yaml:"title,omitempty"`
+	Description  string              `json:"description,omitempty" yaml:"description,omitempty"`
+	Platform     string              `json:"platform" yaml:"platform"`
+	Category     string              `json:"category,omitempty" yaml:"category,omitempty"`
+	Tags         []string            `json:"tags,omitempty" yaml:"tags,omitempty"`
+	PreviewImage string              `json:"previewImage,omitempty" yaml:"previewImage,omitempty"`
+	PreviewDemo  string              `json:"previewDemo,omitempty" yaml:"previewDemo,omitempty"`
+	Variant      string              `json:"variant,omitempty" yaml:"variant,omitempty"`
+	Variants     []componentVariant  `json:"variants,omitempty" yaml:"variants,omitempty"`
+	Dependencies []*dependencyNode   `json:"dependencies,omitempty" yaml:"dependencies,omitempty"`
+	Variables    []componentVariable `json:"variables,omitempty" yaml:"variables,omitempty"`
+	Files        []componentFile     `json:"files" yaml:"files"`
+	Hooks        *config.Hooks       `json:"hooks,omitempty" yaml:"hooks,omitempty"`
+	Packages     *config.Packages    `json:"packages,omitempty" yaml:"packages,omitempty"`
+	Readme       string              `json:"readme,omitempty" yaml:"readme,omitempty"`
+}
+
+// dependencyNode is a dependency with its transitive dependencies
+type dependencyNode struct {
+	Name         string            `json:"name" yaml:"name"`
+	Title        string            `json:"title,omitempty" yaml:"title,omitempty"`
+	Missing      bool              `json:"missing,omitempty" yaml:"missing,omitempty"`
+	Cycle        bool              `json:"cycle,omitempty" yaml:"cycle,omitempty"`
+	Dependencies []*dependencyNode `json:"dependencies,omitempty" yaml:"dependencies,omitempty"`
+}
+
+// componentVariable is a variable used by a component
+type componentVariable struct {
+	Name    string `json:"name" yaml:"name"`
+	Default any    `json:"default,omitempty" yaml:"default,omitempty"`
+	Value   any    `json:"value,omitempty" yaml:"value,omitempty"`
+	Defined bool   `json:"defined" yaml:"defined"`
+}
+
+// componentFile is a file of a component with its destination in the current project
+type componentFile struct {
+	Src         string `json:"src" yaml:"src"`
+	Dst         string `json:"dst" yaml:"dst"`
+	ResolvedDst string `json:"resolvedDst,omitempty" yaml:"resolvedDst,omitempty"`
+	// Insert is set for snippets inserted into the destination
+	Insert *config.Insert `json:"insert,omitempty" yaml:"insert,omitempty"`
+	// Merge strategy for existing destination files
+	Merge string `json:"merge,omitempty" yaml:"merge,omitempty"`
+}
+
+// componentVariant is a variant that can be selected when adding the component
+type componentVariant struct {
+	Name        string `json:"name" yaml:"name"`
+	Title       string `json:"title,omitempty" yaml:"title,omitempty"`
+	Description string `json:"description,omitempty" yaml:"description,omitempty"`
+}
+
+func componentShowCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "show",
+		Usage:     "Show details of a component",
+		ArgsUsage: "component-name",
+		Flags: []cli.Flag{
+			ui.OutputFlag(),
+			&cli.StringFlag{
+				Name:  "variant",
+				Usage: "Show the component with a variant applied (defaults to the variant added to the project)",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			format, err := ui.GetOutputFormat(c)
+			if err != nil {
+				return err
+			}
+
+			projectConfig, reg, err := loadProjectAndRegistry(c)
+			if err != nil {
+				return err
+			}
+
+			componentName := c.Args().First()
+			if componentName == "" {
+				return fmt.Errorf("component name is required")
+			}
+
+			variant := projectConfig.Variants[componentName]
+			if c.IsSet("variant") {
+				variant = c.String("variant")
+			}
+
+			details, err := collectComponentDetails(reg, projectConfig, componentName, variant)
+			if err != nil {
+				return err
+			}
+
+			if format != ui.OutputText {
+				return ui.WriteStructured(os.Stdout, format, details)
+			}
+
+			return printComponentDetails(details)
+		},
+	}
+}
+
+// collectComponentDetails resolves the component with the variant applied and its dependencies, variables, files and README
+func collectComponentDetails(reg *registry.Registry, projectConfig *config.ProjectConfig, name, variant string) (*componentDetails, error) {
+	component, err := reg.ResolveComponent(projectConfig.Platform, name)
+	if err != nil {
+		return nil, err
+	}
+	component, err = component.WithVariant(variant)
+	if err != nil {
+		return nil, err
+	}
+
+	details := &componentDetails{
+		Name:         component.Name,
+		Title:        component.Title,
+		Description:  component.Description,
+		Platform:     component.Platform,
+		Category:     component.Category,
+		Tags:         component.Tags,
+		PreviewImage: component.Preview.Image,
+		PreviewDemo:  component.Preview.Demo,
+		Variant:      component.Variant,
+	}
+	for _, variantName := range component.VariantNames() {
+		details.Variants = append(details.Variants, componentVariant{
+			Name:        variantName,
+			Title:       component.Variants[variantName].Title,
+			Description: component.Variants[variantName].Description,
+		})
+	}
+
+	components, err := reg.ScanComponents()
+	if err != nil {
+		return nil, fmt.Errorf("scanning components: %w", err)
+	}
+	details.Dependencies = dependencyTree(components[projectConfig.Platform], component, map[string]bool{component.Name: true})
+
+	// Collect variables of destination paths, file contents and hooks
+	usedVariables := make(map[string]bool)
+	for name := range component.Variables {
+		usedVariables[name] = true
+	}
+	for _, command := range slices.Concat(component.Hooks.PostAdd, component.Hooks.PreRemove) {
+		for _, name := range template.FindVariables(command) {
+			usedVariables[name] = true
+		}
+	}
+	for _, file := range component.Files {
+		for _, name := range template.FindVariables(file.Dst) {
+			usedVariables[name] = true
+		}
+		content, err := reg.ReadFile(filepath.Join(component.Path, file.Src))
+		if err != nil {
+			return nil, fmt.Errorf("reading source file %s: %w", file.Src, err)
+		}
+		for _, name := range template.FindVariables(string(content)) {
+			usedVariables[name] = true
+		}
+	}
+	for name := range usedVariables {
+		value, defined := projectConfig.Variables[name]
+		if defaultValue, hasDefault := component.Variables[name]; !defined && hasDefault {
+			value, defined = defaultValue, true
+		}
+		details.Variables = append(details.Variables, componentVariable{
+			Name:    name,
+			Default: component.Variables[name],
+			Value:   value,
+			Defined: defined,
+		})
+	}
+	sort.Slice(details.Variables, func(i, j int) bool {
+		return details.Variables[i].Name < details.Variables[j].Name
+	})
+
+	// Destinations can only be resolved if all variables are defined
+	variables := component.ResolveVariables(projectConfig.Variables)
+	for _, file := range component.Files {
+		resolvedDst, _ := template.Resolve(file.Dst, variables)
+		details.Files = append(details.Files, componentFile{
+			Src:         file.Src,
+			Dst:         file.Dst,
+			ResolvedDst: resolvedDst,
+			Insert:      file.Insert,
+			Merge:       file.Merge,
+		})
+	}
+
+	if len(component.Hooks.PostAdd) > 0 || len(component.Hooks.PreRemove) > 0 {
+		details.Hooks = &component.Hooks
+	}
+	if !component.Packages.Empty() {
+		details.Packages = &component.Packages
+	}
+
+	// A README is optional, other sources than a filesystem might not provide it
+	readme, err := reg.ReadFile(filepath.Join(component.Path, componentReadmeFile))
+	if err == nil {
+		details.Readme = string(readme)
+	}
+
+	return details, nil
+}
+
+// dependencyTree resolves the dependencies of a component recursively, visited guards against cycles
+func dependencyTree(components map[string]*config.Component, component *config.Component, visited map[string]bool) []*dependencyNode {
+	var nodes []*dependencyNode
+	for _, name := range component.Dependencies {
+		node := &dependencyNode{Name: name}
+		nodes = append(nodes, node)
+
+		dependency, exists := components[name]
+		if !exists {
+			node.Missing = true
+			continue
+		}
+		node.Title = dependency.Title
+
+		if visited[name] {
+			node.Cycle = true
+			continue
+		}
+		visited[name] = true
+		node.Dependencies = dependencyTree(components, dependency, visited)
+		delete(visited, name)
+	}
+	return nodes
+}
+
+// printComponentDetails prints the details in a human readable form
+func printComponentDetails(details *componentDetails) error {
+	var sb strings.Builder
+
+	title := details.Title
+	if title == "" {
+		title = details.Name
+	}
+	sb.WriteString(ui.TitleStyle.Render(title) + " " + ui.HelpStyle.Render(details.Name) + "\n")
+	if details.Description != "" {
+		sb.WriteString(details.Description + "\n")
+	}
+	sb.WriteString("\n")
+
+	writeField := func(label, value string) {
+		if value != "" {
+			fmt.Fprintf(&sb, "%s %s\n", ui.HelpStyle.Render(fmt.Sprintf("%-10s", label+":")), value)
+		}
+	}
+	writeField("Platform", details.Platform)
+	writeField("Category", details.Category)
+	writeField("Tags", strings.Join(details.Tags, ", "))
+	writeField("Preview", details.PreviewImage)
+	writeField("Demo", details.PreviewDemo)
+	writeField("Variant", details.Variant)
+
+	if len(details.Variants) > 0 {
+		sb.WriteString("\n" + ui.TitleStyle.Render("Variants") + "\n")
+		for _, variant := range details.Variants {
+			marker := " "
+			if variant.Name == details.Variant {
+				marker = "●"
+			}
+			fmt.Fprintf(&sb, "  %s %s", marker, variant.Name)
+			if variant.Title != "" {
+				sb.WriteString(" " + ui.HelpStyle.Render(variant.Title))
+			}
+			sb.WriteString("\n")
+		}
+	}
+
+	if len(details.Dependencies) > 0 {
+		sb.WriteString("\n" + ui.TitleStyle.Render("Dependencies") + "\n")
+		writeDependencyTree(&sb, details.Dependencies, "")
+	}
+
+	if len(details.Variables) > 0 {
+		sb.WriteString("\n" + ui.TitleStyle.Render("Variables") + "\n")
+		for _, variable := range details.Variables {
+			value := ui.ErrorStyle.Render("not defined")
+			if variable.Defined {
+				value = fmt.Sprint(variable.Value)
+			}
+			fmt.Fprintf(&sb, "  %s = %s", variable.Name, value)
+			if variable.Default != nil {
+				sb.WriteString(ui.HelpStyle.Render(fmt.Sprintf(" (default: %v)", variable.Default)))
+			}
+			sb.WriteString("\n")
+		}
+	}
+
+	sb.WriteString("\n" + ui.TitleStyle.Render("Files") + "\n")
+	for _, file := range details.Files {
+		dst := file.ResolvedDst
+		if dst == "" {
+			dst = file.Dst
+		}
+		switch {
+		case file.Insert == nil && file.Merge != "":
+			fmt.Fprintf(&sb, "  %s → %s %s\n", file.Src, dst, ui.HelpStyle.Render(fmt.Sprintf("(merge %s)", file.Merge)))
+		case file.Insert == nil:
+			fmt.Fprintf(&sb, "  %s → %s\n", file.Src, dst)
+		case file.Insert.After != "":
+			fmt.Fprintf(&sb, "  %s ⇢ %s %s\n", file.Src, dst, ui.HelpStyle.Render(fmt.Sprintf("(insert after %q)", file.Insert.After)))
+		case file.Insert.Before != "":
+			fmt.Fprintf(&sb, "  %s ⇢ %s %s\n", file.Src, dst, ui.HelpStyle.Render(fmt.Sprintf("(insert before %q)", file.Insert.Before)))
+		default:
+			fmt.Fprintf(&sb, "  %s ⇢ %s %s\n", file.Src, dst, ui.HelpStyle.Render("(append)"))
+		}
+	}
+
+	if details.Hooks != nil {
+		sb.WriteString("\n" + ui.TitleStyle.Render("Hooks") + "\n")
+		for _, command := range details.Hooks.PostAdd {
+			fmt.Fprintf(&sb, "  %s %s\n", ui.HelpStyle.Render("postAdd:"), command)
+		}
+		for _, command := range details.Hooks.PreRemove {
+			fmt.Fprintf(&sb, "  %s %s\n", ui.HelpStyle.Render("preRemove:"), command)
+		}
+	}
+
+	if details.Packages != nil {
+		sb.WriteString("\n" + ui.TitleStyle.Render("Packages") + "\n")
+		for _, pkg := range install.RequiredPackages([]*config.Component{{Packages: *details.Packages}}) {
+			fmt.Fprintf(&sb, "  %s %s\n", ui.HelpStyle.Render(string(pkg.Ecosystem)+":"), pkg)
+		}
+	}
+
+	fmt.Print(sb.String())
+
+	if details.Readme != "" {
+		rendered, err := renderMarkdown(details.Readme)
+		if err != nil {
+			return err
+		}
+		fmt.Print(rendered)
+	}
+
+	return nil
+}
+
+// writeDependencyTree writes the dependencies as a tree with box-drawing characters
+func writeDependencyTree(sb *strings.Builder, nodes []*dependencyNode, indent string) {
+	for i, node := range nodes {
+		branch, childIndent := "├── ", "│   "
+		if i == len(nodes)-1 {
+			branch, childIndent = "└── ", "    "
+		}
+
+		label := node.Name
+		switch {
+		case node.Missing:
+			label += " " + ui.ErrorStyle.Render("(missing)")
+		case node.Cycle:
+			label += " " + ui.WarningStyle.Render("(cycle)")
+		case node.Title != "":
+			label += " " + ui.HelpStyle.Render(node.Title)
+		}
+
+		fmt.Fprintf(sb, "  %s%s%s\n", indent, branch, label)
+		writeDependencyTree(sb, node.Dependencies, indent+childIndent)
+	}
+}
+
+// renderMarkdown renders markdown for the terminal
+func renderMarkdown(markdown string) (string, error) {
+	style := glamour.WithAutoStyle()
+	if !ui.StylingEnabled() {
+		style = glamour.WithStandardStyle("notty")
+	}
+
+	renderer, err := glamour.NewTermRenderer(style, glamour.WithWordWrap(100))
+	if err != nil {
+		return "", fmt.Errorf("creating markdown renderer: %w", err)
+	}
+
+	rendered, err := renderer.Render(markdown)
+	if err != nil {
+		return "", fmt.Errorf("rendering README: %w", err)
+	}
+
+	return rendered, nil
+}
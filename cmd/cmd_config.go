@@ -2,10 +2,12 @@ package main
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/urfave/cli/v2"
 
 	"github.com/networkteam/shry/config"
+	"github.com/networkteam/shry/registry"
 )
 
 func configCommand() *cli.Command {
@@ -15,8 +17,11 @@ func configCommand() *cli.Command {
 		Subcommands: []*cli.Command{
 			{
 				Name:      "set-auth",
-				Usage:     "Set authentication for a registry",
+				Usage:     "Set authentication for a registry, a host or a path prefix",
 				ArgsUsage: "registry-url",
+				Description: "Passwords and tokens are stored in the secret store, not in config.yaml.\n" +
+					"The default file store is only encrypted with a key derived from SHRY_SECRETS_PASSPHRASE,\n" +
+					"without it the key is generated in secrets.enc.key next to secrets.enc (obfuscation, not protection).",
 				Flags: []cli.Flag{
 					&cli.StringFlag{
 						Name:  "username",
@@ -26,6 +31,14 @@ func configCommand() *cli.Command {
 						Name:  "password",
 						Usage: "Password or token for HTTP authentication",
 					},
+					&cli.StringFlag{
+						Name:  "token",
+						Usage: "Token for HTTP bearer token authentication",
+					},
+					&cli.StringFlag{
+						Name:  "ssh-user",
+						Usage: "User for SSH authentication (defaults to the user of the URL, ~/.ssh/config or git)",
+					},
 					&cli.StringFlag{
 						Name:  "private-key",
 						Usage: "Path to private key file for SSH authentication",
@@ -48,27 +61,36 @@ func configCommand() *cli.Command {
 						return err
 					}
 
-					// Create registry config
-					registryConfig := config.RegistryConfig{}
-
 					// Set HTTP authentication if provided
-					if username := c.String("username"); username != "" {
-						registryConfig.HTTP = &config.HTTPAuth{
+					var authConfig config.RegistryConfig
+					if username, token := c.String("username"), c.String("token"); username != "" || token != "" {
+						authConfig.HTTP = &config.HTTPAuth{
 							Username: username,
 							Password: c.String("password"),
+							Token:    token,
 						}
 					}
 
 					// Set SSH authentication if provided
-					if privateKey := c.String("private-key"); privateKey != "" {
-						registryConfig.SSH = &config.SSHAuth{
-							PrivateKeyPath: privateKey,
+					if c.IsSet("private-key") || c.IsSet("ssh-user") {
+						authConfig.SSH = &config.SSHAuth{
+							User:           c.String("ssh-user"),
+							PrivateKeyPath: c.String("private-key"),
 							Password:       c.String("key-password"),
 						}
 					}
 
-					// Update configuration
-					globalConfig.Registries[registryURL] = registryConfig
+					// Credentials of a registry URL are kept with the registry,
+					// a host or path prefix is a separate auth entry matching all registries below it
+					if _, exists := globalConfig.Registries[registryURL]; !exists && (strings.Contains(registryURL, "://") || registry.IsSSH(registryURL)) {
+						if globalConfig.Registries == nil {
+							globalConfig.Registries = make(map[string]config.RegistryConfig)
+						}
+						globalConfig.Registries[registryURL] = config.RegistryConfig{}
+					}
+
+					// Update configuration, secrets are moved to the secret store and previous secrets deleted on save
+					globalConfig.SetAuth(registryURL, authConfig)
 
 					// Save configuration
 					if err := globalConfig.Save(); err != nil {
@@ -81,7 +103,7 @@ func configCommand() *cli.Command {
 			},
 			{
 				Name:      "remove-auth",
-				Usage:     "Remove authentication for a registry",
+				Usage:     "Remove authentication for a registry, a host or a path prefix",
 				ArgsUsage: "registry-url",
 				Action: func(c *cli.Context) error {
 					// Get registry URL
@@ -96,8 +118,8 @@ func configCommand() *cli.Command {
 						return err
 					}
 
-					// Remove registry configuration
-					delete(globalConfig.Registries, registryURL)
+					// Remove credentials, a registry stays configured and secrets are deleted on save
+					globalConfig.RemoveAuth(registryURL)
 
 					// Save configuration
 					if err := globalConfig.Save(); err != nil {
@@ -108,6 +130,84 @@ func configCommand() *cli.Command {
 					return nil
 				},
 			},
+			{
+				Name:      "trust-hooks",
+				Usage:     "Run hooks of components in a registry without asking",
+				ArgsUsage: "registry-location",
+				Flags: []cli.Flag{
+					&cli.BoolFlag{
+						Name:  "deny",
+						Usage: "Never run hooks of the registry",
+					},
+					&cli.BoolFlag{
+						Name:  "reset",
+						Usage: "Ask again before running hooks of the registry",
+					},
+				},
+				Action: func(c *cli.Context) error {
+					registryLocation := c.Args().First()
+					if registryLocation == "" {
+						return fmt.Errorf("registry location is required")
+					}
+
+					// Load global configuration
+					globalConfig, err := config.LoadGlobalConfig(c.String("global-config"))
+					if err != nil {
+						return err
+					}
+
+					trust := config.HooksTrusted
+					switch {
+					case c.Bool("deny"):
+						trust = config.HooksDenied
+					case c.Bool("reset"):
+						trust = config.HooksAsk
+					}
+					globalConfig.SetHooksTrust(registryLocation, trust)
+
+					// Save configuration
+					if err := globalConfig.Save(); err != nil {
+						return fmt.Errorf("saving configuration: %w", err)
+					}
+
+					switch trust {
+					case config.HooksTrusted:
+						fmt.Printf("Hooks of registry %s are trusted\n", registryLocation)
+					case config.HooksDenied:
+						fmt.Printf("Hooks of registry %s are denied\n", registryLocation)
+					default:
+						fmt.Printf("Asking before running hooks of registry %s\n", registryLocation)
+					}
+					return nil
+				},
+			},
+			{
+				Name:  "migrate-secrets",
+				Usage: "Move plaintext passwords and tokens from the global configuration to the secret store",
+				Description: "The default file store encrypts secrets with a key derived from SHRY_SECRETS_PASSPHRASE.\n" +
+					"Without a passphrase the key is generated in secrets.enc.key next to secrets.enc, which only keeps\n" +
+					"secrets out of config.yaml and does not protect them from anyone who can read both files.",
+				Action: func(c *cli.Context) error {
+					// Load global configuration
+					globalConfig, err := config.LoadGlobalConfig(c.String("global-config"))
+					if err != nil {
+						return err
+					}
+
+					if !globalConfig.HasPlaintextSecrets() {
+						fmt.Println("No plaintext secrets found")
+						return nil
+					}
+
+					// Saving moves all plaintext secrets to the secret store
+					if err := globalConfig.Save(); err != nil {
+						return fmt.Errorf("saving configuration: %w", err)
+					}
+
+					fmt.Println("Moved plaintext secrets to the secret store")
+					return nil
+				},
+			},
 		},
 	}
 }
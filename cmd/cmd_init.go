@@ -3,7 +3,9 @@ package main
 import (
 	"errors"
 	"fmt"
+	"maps"
 	"os"
+	"slices"
 	"strings"
 
 	"github.com/charmbracelet/huh"
@@ -11,6 +13,7 @@ import (
 
 	"github.com/networkteam/shry/config"
 	"github.com/networkteam/shry/registry"
+	"github.com/networkteam/shry/ui"
 )
 
 func initCommand() *cli.Command {
@@ -20,7 +23,7 @@ func initCommand() *cli.Command {
 		Flags: []cli.Flag{
 			&cli.StringFlag{
 				Name:    "registry",
-				Usage:   "Git URL of the component registry (e.g. github.com/networkteam/neos-components[@ref])",
+				Usage:   "Location of the component registry (e.g. github.com/networkteam/neos-components[@ref], git@gitlab.example.com:team/registry.git, file:///path/to/registry.git or a local directory)",
 				Aliases: []string{"r"},
 			},
 			&cli.StringFlag{
@@ -28,13 +31,15 @@ func initCommand() *cli.Command {
 				Usage:   "Platform to use for the project",
 				Aliases: []string{"p"},
 			},
+			&cli.StringSliceFlag{
+				Name:  "var",
+				Usage: "Project variable as `name=value`, required variables of the platform are asked for otherwise",
+			},
 		},
 		Action: func(c *cli.Context) error {
-			registryURL := c.String("registry")
-			if registryURL == "" {
-				fmt.Println("No registry specified")
-				// TODO Show selector for available registries
-				return nil
+			variables, err := parseVariableFlags(c.StringSlice("var"))
+			if err != nil {
+				return err
 			}
 
 			// Load global configuration
@@ -56,12 +61,7 @@ func initCommand() *cli.Command {
 			registrySpec := c.String("registry")
 			if registrySpec != "" {
 				// Split registry URL and reference if present
-				parts := strings.Split(registrySpec, "@")
-				registryLocation = parts[0]
-				ref = ""
-				if len(parts) > 1 {
-					ref = parts[1]
-				}
+				registryLocation, ref = registry.SplitRef(registrySpec)
 			} else {
 				registryLocations, err := globalConfig.RegistryLocations()
 				if err != nil {
@@ -102,29 +102,56 @@ func initCommand() *cli.Command {
 			if err != nil {
 				return fmt.Errorf("failed to get registry: %w", err)
 			}
+			if err := checkRegistryVersion(reg); err != nil {
+				return err
+			}
+
+			manifest, err := reg.Manifest()
+			if err != nil {
+				return err
+			}
+			if manifest == nil {
+				manifest = &config.RegistryManifest{}
+			}
+			if manifest.Name != "" {
+				fmt.Println(ui.TitleStyle.Render(manifest.Name))
+				if manifest.Description != "" {
+					fmt.Println(manifest.Description)
+				}
+			}
 
 			components, err := reg.ScanComponents()
 			if err != nil {
 				return fmt.Errorf("scanning components: %w", err)
 			}
 
+			// Platforms are defined by the manifest or used by components
+			platforms := manifest.PlatformNames()
+			for platform := range components {
+				if !slices.Contains(platforms, platform) {
+					platforms = append(platforms, platform)
+				}
+			}
+			slices.Sort(platforms)
+
 			platform := c.String("platform")
 			if platform != "" {
-				if _, exists := components[platform]; !exists {
+				platform = manifest.ResolvePlatform(platform)
+				if !slices.Contains(platforms, platform) {
 					return fmt.Errorf("platform %s not found in registry", platform)
 				}
 			} else {
 				// Prompt for platform
-				var platforms []string
-				for platform := range components {
-					platforms = append(platforms, platform)
+				platformOpts := make([]huh.Option[string], len(platforms))
+				for i, name := range platforms {
+					platformOpts[i] = huh.NewOption(platformLabel(name, manifest.Platforms[name]), name)
 				}
 
 				err = huh.NewForm(
 					huh.NewGroup(
 						huh.NewSelect[string]().
 							Title("Select a platform").
-							Options(huh.NewOptions(platforms...)...).
+							Options(platformOpts...).
 							Value(&platform),
 					),
 				).Run()
@@ -143,8 +170,33 @@ func initCommand() *cli.Command {
 
 			// Update and save project config
 			projectConfig.Registry = registryLocation
+			if ref != "" {
+				projectConfig.Registry += "@" + ref
+			}
 			projectConfig.Platform = platform
 
+			// Default variables of the platform are added, required variables are asked for if not given
+			if projectConfig.Variables == nil {
+				projectConfig.Variables = make(map[string]any)
+			}
+			maps.Copy(projectConfig.Variables, variables)
+			platformDefinition := manifest.Platforms[platform]
+			for name, value := range platformDefinition.Variables {
+				if _, exists := projectConfig.Variables[name]; !exists {
+					projectConfig.Variables[name] = value
+				}
+			}
+			for _, name := range platformDefinition.RequiredVariables {
+				if _, exists := projectConfig.Variables[name]; exists {
+					continue
+				}
+				value, err := askVariable(name)
+				if err != nil {
+					return err
+				}
+				projectConfig.Variables[name] = value
+			}
+
 			err = projectConfig.Save()
 			if err != nil {
 				return fmt.Errorf("saving project config: %w", err)
@@ -154,3 +206,50 @@ func initCommand() *cli.Command {
 		},
 	}
 }
+
+// platformLabel is the option of a platform in the platform picker
+func platformLabel(name string, platform config.Platform) string {
+	label := name
+	if platform.Title != "" {
+		label = fmt.Sprintf("%s (%s)", platform.Title, name)
+	}
+	if platform.Description != "" {
+		label += " – " + platform.Description
+	}
+	return label
+}
+
+// parseVariableFlags parses `name=value` values of the --var flag
+func parseVariableFlags(values []string) (map[string]any, error) {
+	variables := make(map[string]any)
+	for _, value := range values {
+		name, variableValue, found := strings.Cut(value, "=")
+		if !found || name == "" {
+			return nil, fmt.Errorf("invalid variable %q, use name=value", value)
+		}
+		variables[name] = variableValue
+	}
+	return variables, nil
+}
+
+// askVariable asks for the value of a required project variable
+func askVariable(name string) (string, error) {
+	var value string
+	err := huh.NewForm(
+		huh.NewGroup(
+			huh.NewInput().
+				Title(fmt.Sprintf("Value of required variable %s", name)).
+				Validate(func(s string) error {
+					if s == "" {
+						return fmt.Errorf("value is required")
+					}
+					return nil
+				}).
+				Value(&value),
+		),
+	).Run()
+	if err != nil {
+		return "", err
+	}
+	return value, nil
+}
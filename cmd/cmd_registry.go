@@ -10,6 +10,8 @@ func registryCommand() *cli.Command {
 			registryAddCommand(),
 			registryListCommand(),
 			registryDeleteCommand(),
+			registryBuildCommand(),
+			registryServeCommand(),
 		},
 	}
 }
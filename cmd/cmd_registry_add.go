@@ -11,6 +11,7 @@ import (
 
 	"github.com/networkteam/shry/config"
 	"github.com/networkteam/shry/registry"
+	"github.com/networkteam/shry/ui"
 )
 
 func registryAddCommand() *cli.Command {
@@ -28,6 +29,14 @@ func registryAddCommand() *cli.Command {
 				Name:  "password",
 				Usage: "Password or token for HTTP authentication",
 			},
+			&cli.StringFlag{
+				Name:  "token",
+				Usage: "Token for HTTP bearer token authentication",
+			},
+			&cli.StringFlag{
+				Name:  "ssh-user",
+				Usage: "User for SSH authentication (defaults to the user of the URL, ~/.ssh/config or git)",
+			},
 			&cli.StringFlag{
 				Name:  "private-key",
 				Usage: "Path to private key file for SSH authentication",
@@ -63,32 +72,21 @@ func registryAddCommand() *cli.Command {
 				return fmt.Errorf("getting current directory: %w", err)
 			}
 
+			registryLocation, ref := registry.SplitRef(registryLocation)
 			registryName := registryLocation
 
 			// Try to get the registry to verify it's accessible
-			reg, err := cache.GetRegistry(registryLocation, "", cwd)
+			reg, err := getRegistryTrustingHostKey(cache, registryLocation, ref, cwd)
 			if err != nil {
 				// Check if authentication is required
-				if errors.Is(err, transport.ErrAuthenticationRequired) {
-					var authType string
-					err = huh.NewForm(
-						huh.NewGroup(
-							huh.NewSelect[string]().
-								Title("Authentication required. Choose authentication method:").
-								Options(
-									huh.NewOption("HTTP Basic", "http"),
-									huh.NewOption("SSH Key", "ssh"),
-								).
-								Value(&authType),
-						),
-					).Run()
-					if err != nil {
-						return err
-					}
-
+				if errors.Is(err, transport.ErrAuthenticationRequired) || isSSHAuthError(err) {
 					registryConfig := config.RegistryConfig{}
 
-					if authType == "http" {
+					if token := c.String("token"); token != "" && !registry.IsSSH(registryLocation) {
+						registryConfig.HTTP = &config.HTTPAuth{
+							Token: token,
+						}
+					} else if !registry.IsSSH(registryLocation) {
 						// Use provided flags or prompt for HTTP auth
 						username := c.String("username")
 						password := c.String("password")
@@ -134,6 +132,7 @@ func registryAddCommand() *cli.Command {
 								huh.NewGroup(
 									huh.NewInput().
 										Title("Path to private key file").
+										Description("Leave empty to use identities from ~/.ssh/config and the SSH agent").
 										Value(&privateKey),
 								),
 							).Run()
@@ -157,6 +156,7 @@ func registryAddCommand() *cli.Command {
 						}
 
 						registryConfig.SSH = &config.SSHAuth{
+							User:           c.String("ssh-user"),
 							PrivateKeyPath: privateKey,
 							Password:       keyPassword,
 						}
@@ -169,14 +169,15 @@ func registryAddCommand() *cli.Command {
 					globalConfig.Registries[registryName] = registryConfig
 
 					// Try again with authentication
-					reg, err = cache.GetRegistry(registryName, "", cwd)
+					reg, err = getRegistryTrustingHostKey(cache, registryName, ref, cwd)
 					if err != nil {
 						return fmt.Errorf("failed to access registry with authentication: %w", err)
 					}
 				} else {
 					return fmt.Errorf("failed to access registry: %w", err)
 				}
-			} else {
+			} else if !reg.IsGit() {
+				// Local registries are stored with their absolute path
 				registryName = reg.Name
 			}
 
@@ -208,4 +209,39 @@ func registryAddCommand() *cli.Command {
 			return nil
 		},
 	}
-}
\ No newline at end of file
+}
+
+// getRegistryTrustingHostKey gets the registry and asks the user to trust the host key of an unknown SSH server
+func getRegistryTrustingHostKey(cache *registry.Cache, location string, ref string, projectRoot string) (*registry.Registry, error) {
+	reg, err := cache.GetRegistry(location, ref, projectRoot)
+
+	var hostKeyErr *config.UnknownHostKeyError
+	if !errors.As(err, &hostKeyErr) {
+		return reg, err
+	}
+
+	confirmOptions := ui.NewConfirmation(fmt.Sprintf("The authenticity of host '%s' can't be established. Trust it?", hostKeyErr.Hostname)).
+		WithDescription(fmt.Sprintf("%s key fingerprint is %s.\nThe key will be added to your known_hosts file.", hostKeyErr.Key.Type(), hostKeyErr.Fingerprint())).
+		WithYesText("Trust").
+		WithNoText("Cancel")
+
+	confirmed, err := ui.ShowConfirmation(confirmOptions)
+	if err != nil {
+		return nil, err
+	}
+	if !confirmed {
+		return nil, hostKeyErr
+	}
+
+	if err := config.TrustHostKey(hostKeyErr); err != nil {
+		return nil, fmt.Errorf("trusting host key: %w", err)
+	}
+
+	return cache.GetRegistry(location, ref, projectRoot)
+}
+
+// isSSHAuthError checks if the error is caused by failed SSH authentication
+func isSSHAuthError(err error) bool {
+	var sshAuthErr *config.SSHAuthError
+	return errors.As(err, &sshAuthErr) || errors.Is(err, config.ErrNoSSHIdentities)
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/networkteam/shry/registry"
+)
+
+func registryBuildCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "build",
+		Usage:     "Build a static registry that can be served over HTTP",
+		ArgsUsage: "[registry-location]",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:    "output",
+				Aliases: []string{"o"},
+				Usage:   "Output directory for the static registry",
+				Value:   "dist",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			// Default to the registry in the current directory
+			reg, err := loadRegistryFromArgs(c)
+			if err != nil {
+				return err
+			}
+
+			index, err := registry.BuildStatic(reg, c.String("output"))
+			if err != nil {
+				return fmt.Errorf("building static registry: %w", err)
+			}
+
+			var count int
+			for _, components := range index.Platforms {
+				count += len(components)
+			}
+			fmt.Printf("Built static registry with %d components in %s\n", count, c.String("output"))
+			fmt.Printf("Serve the directory over HTTP and use the URL of %s as registry location\n", registry.StaticIndexFile)
+			return nil
+		},
+	}
+}
@@ -145,8 +145,8 @@ func registryDeleteCommand() *cli.Command {
 				return nil
 			}
 
-			// Remove registry
-			delete(globalConfig.Registries, registryLocation)
+			// Remove registry, its secrets are deleted on save
+			globalConfig.RemoveRegistry(registryLocation)
 
 			// Save configuration
 			if err := globalConfig.Save(); err != nil {
@@ -2,6 +2,7 @@ package main
 
 import (
 	"fmt"
+	"os"
 
 	"github.com/urfave/cli/v2"
 
@@ -14,14 +15,22 @@ func registryListCommand() *cli.Command {
 		Name:    "list",
 		Aliases: []string{"ls"},
 		Usage:   "List configured registries",
+		Flags: []cli.Flag{
+			ui.OutputFlag(),
+		},
 		Action: func(c *cli.Context) error {
+			format, err := ui.GetOutputFormat(c)
+			if err != nil {
+				return err
+			}
+
 			// Load global configuration
 			globalConfig, err := config.LoadGlobalConfig(c.String("global-config"))
 			if err != nil {
 				return err
 			}
 
-			if len(globalConfig.Registries) == 0 {
+			if len(globalConfig.Registries) == 0 && format == ui.OutputText {
 				return fmt.Errorf("no registries configured, you can add new registries with `shry registry add <registry-name>`")
 			}
 
@@ -31,6 +40,13 @@ func registryListCommand() *cli.Command {
 				return err
 			}
 
+			if format != ui.OutputText {
+				if registries == nil {
+					registries = []ui.RegistryInfo{}
+				}
+				return ui.WriteStructured(os.Stdout, format, registries)
+			}
+
 			// Format and display table
 			tableOptions := ui.TableOptions{
 				Title:        "Configured registries:",
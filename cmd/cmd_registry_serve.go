@@ -0,0 +1,57 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/networkteam/shry/registry"
+)
+
+func registryServeCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "serve",
+		Usage:     "Serve a browsable catalog and JSON API of a registry",
+		ArgsUsage: "[registry-location]",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:    "listen",
+				Aliases: []string{"l"},
+				Usage:   "Address to listen on",
+				Value:   "localhost:8080",
+			},
+			&cli.StringFlag{
+				Name:  "title",
+				Usage: "Title of the catalog",
+			},
+			&cli.BoolFlag{
+				Name:  "static",
+				Usage: "Serve the static registry protocol below /r/ for other shry clients",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			// Default to the registry in the current directory
+			reg, err := loadRegistryFromArgs(c)
+			if err != nil {
+				return err
+			}
+
+			handler := registry.NewServer(reg, registry.ServerOptions{
+				Title:  c.String("title"),
+				Static: c.Bool("static"),
+			})
+
+			addr := c.String("listen")
+			fmt.Printf("Serving registry %s at http://%s/\n", reg.Name, addr)
+			if c.Bool("static") {
+				fmt.Printf("Use http://%s/r/%s as registry location\n", addr, registry.StaticIndexFile)
+			}
+
+			if err := http.ListenAndServe(addr, handler); err != nil {
+				return fmt.Errorf("serving registry: %w", err)
+			}
+			return nil
+		},
+	}
+}
@@ -12,8 +12,12 @@ import (
 
 	"github.com/networkteam/shry/config"
 	"github.com/networkteam/shry/registry"
+	"github.com/networkteam/shry/ui"
 )
 
+// version is set by the release build
+var version = "dev"
+
 func main() {
 	home, err := homedir.Dir()
 	if err != nil {
@@ -22,6 +26,12 @@ func main() {
 
 	app := cli.NewApp()
 	app.Name = "shry"
+	app.Version = version
+	// -v is the alias of --verbose
+	cli.VersionFlag = &cli.BoolFlag{
+		Name:  "version",
+		Usage: "print the version",
+	}
 	app.Usage = "A command line tool to add and share components for generic projects and platforms"
 	app.Flags = []cli.Flag{
 		&cli.StringFlag{
@@ -43,10 +53,17 @@ func main() {
 			EnvVars: []string{"SHRY_VERBOSE"},
 		},
 	}
+	app.Before = func(c *cli.Context) error {
+		ui.ConfigureStyling()
+		return nil
+	}
 	app.Commands = []*cli.Command{
 		initCommand(),
 		componentAddCommand(),
 		componentLsCommand(),
+		componentShowCommand(),
+		componentSearchCommand(),
+		componentRemoveCommand(),
 		configCommand(),
 		registryCommand(),
 	}
@@ -61,30 +78,93 @@ func main() {
 }
 
 func loadProjectAndRegistry(c *cli.Context) (*config.ProjectConfig, *registry.Registry, error) {
+	projectConfig, _, reg, err := loadProject(c)
+	return projectConfig, reg, err
+}
+
+// loadProject loads the nearest project config, the global config and the registry of the project
+func loadProject(c *cli.Context) (*config.ProjectConfig, *config.GlobalConfig, *registry.Registry, error) {
 	// Find and load the nearest project config
 	projectConfig, err := config.FindNearestProjectConfig()
 	if err != nil {
-		return nil, nil, err
+		return nil, nil, nil, err
 	}
 
 	// Load global configuration
 	globalConfig, err := config.LoadGlobalConfig(c.String("global-config"))
 	if err != nil {
-		return nil, nil, err
+		return nil, nil, nil, err
 	}
 
 	// Build cache
 	cache, err := registry.NewCache(c.String("cache-dir"), globalConfig)
 	if err != nil {
-		return nil, nil, err
+		return nil, nil, nil, err
 	}
 	cache.Verbose = c.Bool("verbose")
 
 	// Get registry for the current project
-	reg, err := cache.GetRegistry(projectConfig.Registry, "", projectConfig.ProjectDir)
+	registryLocation, ref := registry.SplitRef(projectConfig.Registry)
+	reg, err := cache.GetRegistry(registryLocation, ref, projectConfig.ProjectDir)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if err := checkRegistryVersion(reg); err != nil {
+		return nil, nil, nil, err
+	}
+
+	// The project can use an alias of the platform
+	projectConfig.Platform, err = reg.ResolvePlatform(projectConfig.Platform)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	return projectConfig, globalConfig, reg, nil
+}
+
+// checkRegistryVersion returns an error if the registry manifest requires a newer version of shry
+func checkRegistryVersion(reg *registry.Registry) error {
+	manifest, err := reg.Manifest()
+	if err != nil {
+		return err
+	}
+	if manifest == nil {
+		return nil
+	}
+	return manifest.CheckVersion(version)
+}
+
+// loadRegistryFromArgs loads the registry given as first argument, defaulting to the current directory
+func loadRegistryFromArgs(c *cli.Context) (*registry.Registry, error) {
+	// Load global configuration
+	globalConfig, err := config.LoadGlobalConfig(c.String("global-config"))
+	if err != nil {
+		return nil, err
+	}
+
+	// Build cache
+	cache, err := registry.NewCache(c.String("cache-dir"), globalConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cache: %w", err)
+	}
+	cache.Verbose = c.Bool("verbose")
+
+	// Get current directory for resolving relative paths
+	cwd, err := os.Getwd()
+	if err != nil {
+		return nil, fmt.Errorf("getting current directory: %w", err)
+	}
+
+	registrySpec := c.Args().First()
+	if registrySpec == "" {
+		registrySpec = "."
+	}
+	registryLocation, ref := registry.SplitRef(registrySpec)
+
+	reg, err := cache.GetRegistry(registryLocation, ref, cwd)
 	if err != nil {
-		return nil, nil, err
+		return nil, fmt.Errorf("failed to get registry: %w", err)
 	}
 
-	return projectConfig, reg, nil
+	return reg, nil
 }
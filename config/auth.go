@@ -0,0 +1,252 @@
+package config
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"log/slog"
+	"net"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/go-git/go-git/v5/plumbing/transport/http"
+)
+
+const (
+	// EnvAuthToken is the environment variable holding a token for HTTPS registries without other credentials.
+	// It is only sent to hosts with an auth config or listed in EnvAuthHosts.
+	// A host specific variable is built by appending the upper-cased host (e.g. SHRY_AUTH_TOKEN_GITLAB_EXAMPLE_COM).
+	EnvAuthToken = "SHRY_AUTH_TOKEN"
+	// EnvAuthHosts is the environment variable with a comma separated list of hosts that get the token of EnvAuthToken.
+	EnvAuthHosts = "SHRY_AUTH_HOSTS"
+	// EnvAuthUsername is the environment variable holding the username for the token.
+	// If no username is set, the token is sent as bearer token.
+	EnvAuthUsername = "SHRY_AUTH_USERNAME"
+)
+
+// GetAuth returns the authentication method for the given registry location and its Git endpoint.
+//
+// Credentials are resolved in the following order:
+//   - the host specific token from the environment (HTTP only)
+//   - the registry config with the exact location
+//   - the auth config with the longest matching host or path prefix
+//   - git credential helpers (HTTP only)
+//   - the token from the environment for configured hosts (HTTPS only)
+func (c *GlobalConfig) GetAuth(location string, endpoint *transport.Endpoint) (transport.AuthMethod, error) {
+	registryConfig := c.lookupRegistryConfig(location, endpoint)
+
+	switch endpoint.Protocol {
+	case "http", "https":
+		if auth := envTokenAuth("_" + envHostSuffix(endpoint.Host)); auth != nil {
+			return auth, nil
+		}
+		if registryConfig.HTTP != nil {
+			return c.httpAuth(registryConfig.HTTP)
+		}
+		if !c.DisableGitCredentials {
+			auth, err := gitCredentialAuth(endpoint)
+			if auth != nil || err != nil {
+				return auth, err
+			}
+		}
+		// The global token is never sent unencrypted or to hosts the user did not configure,
+		// registries of other parties (e.g. shadcn or static registries) must not receive it
+		if endpoint.Protocol == "https" && c.isAuthHost(endpoint.Host) {
+			return envTokenAuth(""), nil
+		}
+	case "ssh":
+		// SSH always needs an auth method, without registry config the SSH agent and ~/.ssh/config are used
+		sshConfig := registryConfig.SSH
+		if sshConfig != nil && sshConfig.PasswordRef != "" {
+			password, err := c.resolveSecret(sshConfig.Password, sshConfig.PasswordRef)
+			if err != nil {
+				return nil, err
+			}
+			resolved := *sshConfig
+			resolved.Password = password
+			sshConfig = &resolved
+		}
+		return sshAuth(endpoint, sshConfig)
+	}
+
+	return nil, nil
+}
+
+// httpAuth returns the HTTP authentication method for the config, resolving referenced secrets
+func (c *GlobalConfig) httpAuth(a *HTTPAuth) (transport.AuthMethod, error) {
+	token, err := c.resolveSecret(a.Token, a.TokenRef)
+	if err != nil {
+		return nil, err
+	}
+	if token != "" {
+		return &http.TokenAuth{Token: token}, nil
+	}
+
+	password, err := c.resolveSecret(a.Password, a.PasswordRef)
+	if err != nil {
+		return nil, err
+	}
+	return &http.BasicAuth{
+		Username: a.Username,
+		Password: password,
+	}, nil
+}
+
+// lookupRegistryConfig finds the registry config for a location.
+// If the registry has no credentials, the auth config with the longest host or path prefix
+// matching the endpoint is used, so one entry can cover all repositories on a host.
+func (c *GlobalConfig) lookupRegistryConfig(location string, endpoint *transport.Endpoint) RegistryConfig {
+	if registryConfig, exists := c.Registries[location]; exists && registryConfig.hasAuth() {
+		return registryConfig
+	}
+
+	targetPath := normalizeRepositoryPath(endpoint.Path)
+
+	var bestMatch RegistryConfig
+	bestLength := -1
+	for key, authConfig := range c.Auth {
+		if !authConfig.hasAuth() {
+			continue
+		}
+
+		keyHost, keyPath := splitAuthKey(key)
+		if !strings.EqualFold(keyHost, endpoint.Host) {
+			continue
+		}
+		if keyPath != "" && targetPath != keyPath && !strings.HasPrefix(targetPath, keyPath+"/") {
+			continue
+		}
+
+		if len(keyPath) > bestLength {
+			bestMatch = authConfig
+			bestLength = len(keyPath)
+		}
+	}
+
+	return bestMatch
+}
+
+// hasAuth checks if the registry config contains any authentication information
+func (r RegistryConfig) hasAuth() bool {
+	return r.HTTP != nil || r.SSH != nil
+}
+
+// isAuthHost checks if the host has an auth config or is listed in the environment to receive the global token
+func (c *GlobalConfig) isAuthHost(host string) bool {
+	for _, authHost := range strings.Split(os.Getenv(EnvAuthHosts), ",") {
+		if strings.EqualFold(strings.TrimSpace(authHost), host) {
+			return true
+		}
+	}
+	for key := range c.Auth {
+		if keyHost, _ := splitAuthKey(key); strings.EqualFold(keyHost, host) {
+			return true
+		}
+	}
+	return false
+}
+
+// splitAuthKey splits an auth config key (a host or a host with a path prefix) into the host and the normalized path
+func splitAuthKey(key string) (host string, path string) {
+	if _, rest, found := strings.Cut(key, "://"); found {
+		key = rest
+	}
+	host, path, _ = strings.Cut(key, "/")
+	if _, rest, found := strings.Cut(host, "@"); found {
+		host = rest
+	}
+	if hostname, _, err := net.SplitHostPort(host); err == nil {
+		host = hostname
+	}
+	return host, normalizeRepositoryPath(path)
+}
+
+// normalizeRepositoryPath strips slashes and a .git suffix from a repository path
+func normalizeRepositoryPath(path string) string {
+	return strings.TrimSuffix(strings.Trim(path, "/"), ".git")
+}
+
+// envTokenAuth returns an authentication method from the environment variables with the given suffix
+// (empty for the token of all hosts)
+func envTokenAuth(suffix string) transport.AuthMethod {
+	token := os.Getenv(EnvAuthToken + suffix)
+	if token == "" {
+		return nil
+	}
+
+	username := firstNonEmpty(os.Getenv(EnvAuthUsername+suffix), os.Getenv(EnvAuthUsername))
+	if username == "" {
+		return &http.TokenAuth{Token: token}
+	}
+	return &http.BasicAuth{
+		Username: username,
+		Password: token,
+	}
+}
+
+// envHostSuffix converts a host to an environment variable suffix (e.g. gitlab.example.com -> GITLAB_EXAMPLE_COM)
+func envHostSuffix(host string) string {
+	return strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+			return r
+		}
+		return '_'
+	}, strings.ToUpper(host))
+}
+
+// gitCredentialAuth queries the configured git credential helpers via `git credential fill`.
+// It returns no authentication method if git is not installed or no credentials are available.
+func gitCredentialAuth(endpoint *transport.Endpoint) (transport.AuthMethod, error) {
+	if _, err := exec.LookPath("git"); err != nil {
+		return nil, nil
+	}
+
+	host := endpoint.Host
+	if endpoint.Port > 0 {
+		host = fmt.Sprintf("%s:%d", endpoint.Host, endpoint.Port)
+	}
+
+	var input bytes.Buffer
+	fmt.Fprintf(&input, "protocol=%s\n", endpoint.Protocol)
+	fmt.Fprintf(&input, "host=%s\n", host)
+	fmt.Fprintf(&input, "path=%s\n", strings.TrimPrefix(endpoint.Path, "/"))
+	if endpoint.User != "" {
+		fmt.Fprintf(&input, "username=%s\n", endpoint.User)
+	}
+	input.WriteString("\n")
+
+	cmd := exec.Command("git", "credential", "fill")
+	cmd.Stdin = &input
+	// Never prompt for credentials, the user is asked by shry if needed
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0", "GIT_ASKPASS=", "SSH_ASKPASS=")
+	output, err := cmd.Output()
+	if err != nil {
+		slog.Debug("No credentials from git credential helper", "host", host, "error", err)
+		return nil, nil
+	}
+
+	credentials := parseCredentialOutput(output)
+	if credentials["password"] == "" {
+		return nil, nil
+	}
+
+	return &http.BasicAuth{
+		Username: credentials["username"],
+		Password: credentials["password"],
+	}, nil
+}
+
+// parseCredentialOutput parses the key=value lines of the git credential protocol
+func parseCredentialOutput(output []byte) map[string]string {
+	values := make(map[string]string)
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	for scanner.Scan() {
+		key, value, found := strings.Cut(scanner.Text(), "=")
+		if found {
+			values[key] = value
+		}
+	}
+	return values
+}
@@ -0,0 +1,159 @@
+package config_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/go-git/go-git/v5/plumbing/transport/http"
+
+	"github.com/networkteam/shry/config"
+)
+
+func TestGetAuth(t *testing.T) {
+	globalConfig := &config.GlobalConfig{
+		Registries: map[string]config.RegistryConfig{
+			"gitlab.example.com/team/special-registry": {
+				HTTP: &config.HTTPAuth{Token: "special-token"},
+			},
+			"github.com/networkteam/neos-components": {},
+		},
+		Auth: map[string]config.RegistryConfig{
+			"gitlab.example.com": {
+				HTTP: &config.HTTPAuth{Username: "host", Password: "host-secret"},
+			},
+			"gitlab.example.com/team": {
+				HTTP: &config.HTTPAuth{Username: "team", Password: "team-secret"},
+			},
+			"git.example.com/team": {
+				HTTP: &config.HTTPAuth{Token: "git-team-token"},
+			},
+		},
+		DisableGitCredentials: true,
+	}
+
+	tests := []struct {
+		name     string
+		location string
+		env      map[string]string
+		expected transport.AuthMethod
+	}{
+		{
+			name:     "exact location",
+			location: "gitlab.example.com/team/special-registry",
+			expected: &http.TokenAuth{Token: "special-token"},
+		},
+		{
+			name:     "path prefix",
+			location: "https://gitlab.example.com/team/registry.git",
+			expected: &http.BasicAuth{Username: "team", Password: "team-secret"},
+		},
+		{
+			name:     "path prefix only matches full segments",
+			location: "gitlab.example.com/teamwork/registry",
+			expected: &http.BasicAuth{Username: "host", Password: "host-secret"},
+		},
+		{
+			name:     "host",
+			location: "gitlab.example.com/other/registry",
+			expected: &http.BasicAuth{Username: "host", Password: "host-secret"},
+		},
+		{
+			name:     "no matching config",
+			location: "github.com/networkteam/neos-components",
+			expected: nil,
+		},
+		{
+			name:     "host specific environment token",
+			location: "gitlab.example.com/team/registry",
+			env: map[string]string{
+				"SHRY_AUTH_TOKEN_GITLAB_EXAMPLE_COM":    "ci-token",
+				"SHRY_AUTH_USERNAME_GITLAB_EXAMPLE_COM": "gitlab-ci-token",
+			},
+			expected: &http.BasicAuth{Username: "gitlab-ci-token", Password: "ci-token"},
+		},
+		{
+			name:     "global environment token for listed host without username",
+			location: "github.com/networkteam/neos-components",
+			env: map[string]string{
+				"SHRY_AUTH_TOKEN": "global-token",
+				"SHRY_AUTH_HOSTS": "gitlab.company.com, github.com",
+			},
+			expected: &http.TokenAuth{Token: "global-token"},
+		},
+		{
+			name:     "global environment token for host with auth config",
+			location: "git.example.com/other/registry",
+			env: map[string]string{
+				"SHRY_AUTH_TOKEN": "global-token",
+			},
+			expected: &http.TokenAuth{Token: "global-token"},
+		},
+		{
+			name:     "global environment token is not sent to unconfigured hosts",
+			location: "https://ui.shadcn.com/r/button.json",
+			env: map[string]string{
+				"SHRY_AUTH_TOKEN": "global-token",
+			},
+			expected: nil,
+		},
+		{
+			name:     "global environment token does not override configured credentials",
+			location: "gitlab.example.com/team/registry",
+			env: map[string]string{
+				"SHRY_AUTH_TOKEN": "global-token",
+			},
+			expected: &http.BasicAuth{Username: "team", Password: "team-secret"},
+		},
+		{
+			name:     "global environment token is not sent over plain http",
+			location: "http://registry.example.com/registry.git",
+			env: map[string]string{
+				"SHRY_AUTH_TOKEN": "global-token",
+			},
+			expected: nil,
+		},
+		{
+			name:     "host specific environment token over plain http",
+			location: "http://registry.example.com/registry.git",
+			env: map[string]string{
+				"SHRY_AUTH_TOKEN_REGISTRY_EXAMPLE_COM": "host-token",
+			},
+			expected: &http.TokenAuth{Token: "host-token"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for key, value := range tt.env {
+				t.Setenv(key, value)
+			}
+
+			url := tt.location
+			if !strings.Contains(url, "://") {
+				url = "https://" + url
+			}
+			endpoint, err := transport.NewEndpoint(url)
+			if err != nil {
+				t.Fatalf("NewEndpoint() unexpected error: %v", err)
+			}
+
+			got, err := globalConfig.GetAuth(tt.location, endpoint)
+			if err != nil {
+				t.Errorf("GetAuth() unexpected error: %v", err)
+				return
+			}
+
+			if tt.expected == nil {
+				if got != nil {
+					t.Errorf("GetAuth() = %v, want nil", got)
+				}
+				return
+			}
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("GetAuth() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
@@ -2,7 +2,10 @@ package config
 
 import (
 	"fmt"
+	"maps"
 	"path/filepath"
+	"slices"
+	"strings"
 
 	"github.com/go-git/go-billy/v5"
 	"github.com/networkteam/shry/template"
@@ -23,36 +26,99 @@ type ComponentKey struct {
 // Component represents a component configuration
 type Component struct {
 	// Path is the directory path of the component in the filesystem
-	Path string `yaml:"-"`
+	Path string `yaml:"-" json:"-"`
 	// Name of the component, will be used to reference the component (must be unique within the registry per platform)
-	Name string `yaml:"name"`
+	Name string `yaml:"name" json:"name"`
 	// Optional title (e.g. image-card vs. "Image Card")
-	Title string `yaml:"title,omitempty"`
+	Title string `yaml:"title,omitempty" json:"title,omitempty"`
 	// Optional description
-	Description string `yaml:"description,omitempty"`
+	Description string `yaml:"description,omitempty" json:"description,omitempty"`
 	// Platform this component is for (required)
-	Platform string `yaml:"platform"`
+	Platform string `yaml:"platform" json:"platform"`
 	// Optional category for grouping components
-	Category string `yaml:"category,omitempty"`
+	Category string `yaml:"category,omitempty" json:"category,omitempty"`
+	// Optional tags for searching components
+	Tags []string `yaml:"tags,omitempty" json:"tags,omitempty"`
 	// Optional preview image and demo URL
 	Preview struct {
-		Image string `yaml:"image,omitempty"`
-		Demo  string `yaml:"demo,omitempty"`
-	} `yaml:"preview,omitempty"`
+		Image string `yaml:"image,omitempty" json:"image,omitempty"`
+		Demo  string `yaml:"demo,omitempty" json:"demo,omitempty"`
+	} `yaml:"preview,omitempty" json:"preview"`
 	// Default variables for the component (optional)
-	Variables map[string]any `yaml:"variables,omitempty"`
+	Variables map[string]any `yaml:"variables,omitempty" json:"variables,omitempty"`
 	// Files to copy when adding the component to a project
-	Files []File `yaml:"files"`
+	Files []File `yaml:"files" json:"files"`
 	// Optional list of component dependencies
-	Dependencies []string `yaml:"dependencies,omitempty"`
+	Dependencies []string `yaml:"dependencies,omitempty" json:"dependencies,omitempty"`
+	// Optional hooks to run when the component is added or removed
+	Hooks Hooks `yaml:"hooks,omitempty" json:"hooks,omitempty"`
+	// Optional packages the component requires in the project
+	Packages Packages `yaml:"packages,omitempty" json:"packages,omitempty"`
+	// Optional variants by name, selected when adding the component
+	Variants map[string]Variant `yaml:"variants,omitempty" json:"variants,omitempty"`
+	// Variant is the name of the applied variant, empty for the base component
+	Variant string `yaml:"-" json:"-"`
+}
+
+// Variant overrides files, variables or dependencies of the base component
+type Variant struct {
+	// Optional title of the variant
+	Title string `yaml:"title,omitempty" json:"title,omitempty"`
+	// Optional description of the variant
+	Description string `yaml:"description,omitempty" json:"description,omitempty"`
+	// Variables override the default variables of the component
+	Variables map[string]any `yaml:"variables,omitempty" json:"variables,omitempty"`
+	// Files replace files of the component with the same destination, other files are added
+	Files []File `yaml:"files,omitempty" json:"files,omitempty"`
+	// Dependencies are added to the dependencies of the component
+	Dependencies []string `yaml:"dependencies,omitempty" json:"dependencies,omitempty"`
+}
+
+// Packages are package requirements per package manager, mapping package names to version constraints.
+// An empty version requires any version.
+type Packages struct {
+	// NPM packages in package.json
+	NPM map[string]string `yaml:"npm,omitempty" json:"npm,omitempty"`
+	// Composer packages in composer.json
+	Composer map[string]string `yaml:"composer,omitempty" json:"composer,omitempty"`
+	// Go modules in go.mod
+	Go map[string]string `yaml:"go,omitempty" json:"go,omitempty"`
+}
+
+// Empty returns true if no packages are required
+func (p Packages) Empty() bool {
+	return len(p.NPM) == 0 && len(p.Composer) == 0 && len(p.Go) == 0
+}
+
+// Hooks are shell commands run in the project directory, variables in commands are resolved
+type Hooks struct {
+	// PostAdd commands run after the files of the component were added
+	PostAdd []string `yaml:"postAdd,omitempty" json:"postAdd,omitempty"`
+	// PreRemove commands run before the files of the component are removed
+	PreRemove []string `yaml:"preRemove,omitempty" json:"preRemove,omitempty"`
 }
 
 // File represents a file to be copied when adding a component
 type File struct {
 	// Src file relative to the component directory
-	Src string `yaml:"src"`
+	Src string `yaml:"src" json:"src"`
 	// Destination path (filename with variables)
-	Dst string `yaml:"dst"`
+	Dst string `yaml:"dst" json:"dst"`
+	// Insert the content of Src as a snippet into the destination instead of copying the file (optional)
+	Insert *Insert `yaml:"insert,omitempty" json:"insert,omitempty"`
+	// Merge strategy for existing destination files: auto, yaml, json or xliff (optional)
+	Merge string `yaml:"merge,omitempty" json:"merge,omitempty"`
+}
+
+// Insert describes where a snippet is inserted into an existing file.
+// Without an anchor the snippet is appended to the end of the file.
+type Insert struct {
+	// After inserts the snippet after the first line containing this text
+	After string `yaml:"after,omitempty" json:"after,omitempty"`
+	// Before inserts the snippet before the first line containing this text
+	Before string `yaml:"before,omitempty" json:"before,omitempty"`
+	// Comment syntax for the markers around the snippet, e.g. "#" or "<!-- -->" (defaults by file extension)
+	Comment string `yaml:"comment,omitempty" json:"comment,omitempty"`
 }
 
 // LoadComponent loads a component configuration from a filesystem
@@ -139,6 +205,69 @@ func ScanComponents(fs billy.Filesystem, path string) (map[string]map[string]*Co
 	return components, nil
 }
 
+// VariantNames returns the names of all variants sorted by name
+func (c *Component) VariantNames() []string {
+	names := make([]string, 0, len(c.Variants))
+	for name := range c.Variants {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// WithVariant returns a copy of the component with the overrides of the variant applied.
+// An empty name returns the base component.
+func (c *Component) WithVariant(name string) (*Component, error) {
+	if name == "" {
+		return c, nil
+	}
+	variant, exists := c.Variants[name]
+	if !exists {
+		if len(c.Variants) == 0 {
+			return nil, fmt.Errorf("component %s has no variants", c.Name)
+		}
+		return nil, fmt.Errorf("variant %s of component %s not found, available variants: %s", name, c.Name, strings.Join(c.VariantNames(), ", "))
+	}
+
+	resolved := *c
+	resolved.Variant = name
+
+	resolved.Variables = maps.Clone(c.Variables)
+	if resolved.Variables == nil && len(variant.Variables) > 0 {
+		resolved.Variables = make(map[string]any)
+	}
+	maps.Copy(resolved.Variables, variant.Variables)
+
+	resolved.Files = slices.Clone(c.Files)
+	for _, file := range variant.Files {
+		idx := slices.IndexFunc(resolved.Files, func(f File) bool { return f.Dst == file.Dst })
+		if idx >= 0 {
+			resolved.Files[idx] = file
+			continue
+		}
+		resolved.Files = append(resolved.Files, file)
+	}
+
+	resolved.Dependencies = slices.Clone(c.Dependencies)
+	for _, dependency := range variant.Dependencies {
+		if !slices.Contains(resolved.Dependencies, dependency) {
+			resolved.Dependencies = append(resolved.Dependencies, dependency)
+		}
+	}
+
+	return &resolved, nil
+}
+
+// ResolveVariables returns the default variables of the component overridden by the project variables
+func (c *Component) ResolveVariables(projectVariables map[string]any) map[string]any {
+	variables := maps.Clone(c.Variables)
+	if variables == nil {
+		variables = make(map[string]any)
+	}
+	maps.Copy(variables, projectVariables)
+	return variables
+}
+
 // ResolveFiles resolves all variables in the component's files
 func (c *Component) ResolveFiles(variables map[string]any) ([]File, error) {
 	var resolvedFiles []File
@@ -167,9 +296,23 @@ func (c *Component) ResolveFiles(variables map[string]any) ([]File, error) {
 			return nil, fmt.Errorf("resolving destination path %s: %w", file.Dst, err)
 		}
 
+		// Resolve anchors of snippets
+		var insert *Insert
+		if file.Insert != nil {
+			insert = &Insert{Comment: file.Insert.Comment}
+			if insert.After, err = template.Resolve(file.Insert.After, variables); err != nil {
+				return nil, fmt.Errorf("resolving insert anchor of %s: %w", file.Src, err)
+			}
+			if insert.Before, err = template.Resolve(file.Insert.Before, variables); err != nil {
+				return nil, fmt.Errorf("resolving insert anchor of %s: %w", file.Src, err)
+			}
+		}
+
 		resolvedFiles = append(resolvedFiles, File{
-			Src: file.Src,
-			Dst: dst,
+			Src:    file.Src,
+			Dst:    dst,
+			Insert: insert,
+			Merge:  file.Merge,
 		})
 	}
 
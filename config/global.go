@@ -2,13 +2,11 @@ package config
 
 import (
 	"fmt"
+	"log/slog"
 	"os"
 	"path/filepath"
 	"slices"
 
-	"github.com/go-git/go-git/v5/plumbing/transport"
-	"github.com/go-git/go-git/v5/plumbing/transport/http"
-	"github.com/go-git/go-git/v5/plumbing/transport/ssh"
 	"gopkg.in/yaml.v3"
 )
 
@@ -21,8 +19,26 @@ const (
 type GlobalConfig struct {
 	// ConfigPath is the path to the global configuration file
 	ConfigPath string `yaml:"-"`
-	// Registries contains authentication information for each registry
+	// Registries contains the configured registries with their authentication information
 	Registries map[string]RegistryConfig `yaml:"registries"`
+	// Auth contains authentication information for a host (e.g. gitlab.example.com) or a host with
+	// a path prefix (e.g. gitlab.example.com/team), used for all registries below it without own credentials
+	Auth map[string]RegistryConfig `yaml:"auth,omitempty"`
+	// Hooks contains the decision whether hooks of components are run for each registry location
+	Hooks map[string]HooksTrust `yaml:"hooks,omitempty"`
+	// DisableGitCredentials disables querying git credential helpers for HTTP registries
+	DisableGitCredentials bool `yaml:"disableGitCredentials,omitempty"`
+	// Secrets configures where passwords and tokens are stored, the configuration only keeps references
+	Secrets SecretStoreConfig `yaml:"secrets,omitempty"`
+	// DiffTool is a shell command to compare an existing file with its upstream version,
+	// $LOCAL and $REMOTE are set to the paths of both files like for git difftool
+	DiffTool string `yaml:"diffTool,omitempty"`
+	// MergeTool is a shell command to merge the upstream version into an existing file,
+	// the result must be written to $MERGED like for git mergetool
+	MergeTool string `yaml:"mergeTool,omitempty"`
+
+	secretStore     SecretStore
+	staleSecretRefs []string
 }
 
 // RegistryConfig contains authentication information for a registry
@@ -36,17 +52,27 @@ type RegistryConfig struct {
 // HTTPAuth contains HTTP authentication information
 type HTTPAuth struct {
 	// Username for HTTP authentication
-	Username string `yaml:"username"`
-	// Password or token for HTTP authentication
-	Password string `yaml:"password"`
+	Username string `yaml:"username,omitempty"`
+	// Password or token for HTTP authentication (plaintext, moved to the secret store on save)
+	Password string `yaml:"password,omitempty"`
+	// PasswordRef references the password in the secret store
+	PasswordRef string `yaml:"passwordRef,omitempty"`
+	// Token for bearer token authentication (plaintext, moved to the secret store on save)
+	Token string `yaml:"token,omitempty"`
+	// TokenRef references the token in the secret store
+	TokenRef string `yaml:"tokenRef,omitempty"`
 }
 
 // SSHAuth contains SSH authentication information
 type SSHAuth struct {
-	// Path to the private key file
-	PrivateKeyPath string `yaml:"privateKeyPath"`
-	// Password for the private key (if encrypted)
+	// User for SSH authentication (defaults to the user of the URL, ~/.ssh/config or "git")
+	User string `yaml:"user,omitempty"`
+	// Path to the private key file (if empty, identities from ~/.ssh/config and the SSH agent are used)
+	PrivateKeyPath string `yaml:"privateKeyPath,omitempty"`
+	// Password for the private key (plaintext, moved to the secret store on save)
 	Password string `yaml:"password,omitempty"`
+	// PasswordRef references the password for the private key in the secret store
+	PasswordRef string `yaml:"passwordRef,omitempty"`
 }
 
 // LoadGlobalConfig loads the global configuration
@@ -74,6 +100,10 @@ func LoadGlobalConfig(configPath string) (*GlobalConfig, error) {
 
 	config.ConfigPath = configPath
 
+	if config.HasPlaintextSecrets() {
+		slog.Warn("Global config contains plaintext secrets, run `shry config migrate-secrets` to move them to the secret store", "path", configPath)
+	}
+
 	return &config, nil
 }
 
@@ -86,54 +116,32 @@ func (c *GlobalConfig) Save() error {
 		return fmt.Errorf("creating global config directory: %w", err)
 	}
 
-	file, err := os.Create(configPath)
+	// Never write plaintext secrets to the configuration
+	if err := c.storeSecrets(); err != nil {
+		return fmt.Errorf("storing secrets: %w", err)
+	}
+
+	file, err := os.OpenFile(configPath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
 	if err != nil {
 		return fmt.Errorf("creating global config: %w", err)
 	}
 	defer file.Close()
 
+	// Restrict permissions of configurations created with default permissions
+	if err := file.Chmod(0600); err != nil {
+		return fmt.Errorf("setting global config permissions: %w", err)
+	}
+
 	// Encode the configuration as YAML
 	if err := yaml.NewEncoder(file).Encode(c); err != nil {
 		return fmt.Errorf("writing global config: %w", err)
 	}
-
-	return nil
-}
-
-// GetAuth returns the authentication method for the given registry URL
-func (c *GlobalConfig) GetAuth(registryURL string) (transport.AuthMethod, error) {
-	// Look up registry configuration
-	config, exists := c.Registries[registryURL]
-	if !exists {
-		return nil, nil
-	}
-
-	// Try HTTP authentication first
-	if config.HTTP != nil {
-		return &http.BasicAuth{
-			Username: config.HTTP.Username,
-			Password: config.HTTP.Password,
-		}, nil
-	}
-
-	// Try SSH authentication
-	if config.SSH != nil {
-		// Read private key
-		key, err := os.ReadFile(config.SSH.PrivateKeyPath)
-		if err != nil {
-			return nil, fmt.Errorf("reading private key: %w", err)
-		}
-
-		// Create SSH auth
-		auth, err := ssh.NewPublicKeys("git", key, config.SSH.Password)
-		if err != nil {
-			return nil, fmt.Errorf("creating SSH auth: %w", err)
-		}
-
-		return auth, nil
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("writing global config: %w", err)
 	}
 
-	return nil, nil
+	// Secrets of removed credentials are only deleted once the configuration no longer references them
+	return c.deleteStaleSecrets()
 }
 
 // RegistryLocations returns a sorted list of registry locations
@@ -0,0 +1,30 @@
+package config
+
+// HooksTrust is the remembered decision whether hooks of a registry are run
+type HooksTrust string
+
+const (
+	// HooksAsk asks before running hooks of the registry
+	HooksAsk HooksTrust = ""
+	// HooksTrusted runs hooks of the registry without asking
+	HooksTrusted HooksTrust = "trusted"
+	// HooksDenied never runs hooks of the registry
+	HooksDenied HooksTrust = "denied"
+)
+
+// HooksTrust returns the decision whether hooks of the registry location are run
+func (c *GlobalConfig) HooksTrust(location string) HooksTrust {
+	return c.Hooks[location]
+}
+
+// SetHooksTrust remembers the decision whether hooks of the registry location are run
+func (c *GlobalConfig) SetHooksTrust(location string, trust HooksTrust) {
+	if trust == HooksAsk {
+		delete(c.Hooks, location)
+		return
+	}
+	if c.Hooks == nil {
+		c.Hooks = make(map[string]HooksTrust)
+	}
+	c.Hooks[location] = trust
+}
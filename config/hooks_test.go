@@ -0,0 +1,37 @@
+package config_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/networkteam/shry/config"
+)
+
+func TestSetHooksTrustDoesNotAddRegistry(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), config.GlobalConfigFile)
+
+	globalConfig, err := config.LoadGlobalConfig(configPath)
+	if err != nil {
+		t.Fatalf("LoadGlobalConfig() unexpected error: %v", err)
+	}
+	globalConfig.SetHooksTrust("github.com/networkteam/neos-components", config.HooksTrusted)
+	if err := globalConfig.Save(); err != nil {
+		t.Fatalf("Save() unexpected error: %v", err)
+	}
+
+	reloaded, err := config.LoadGlobalConfig(configPath)
+	if err != nil {
+		t.Fatalf("LoadGlobalConfig() unexpected error: %v", err)
+	}
+	if locations, _ := reloaded.RegistryLocations(); len(locations) != 0 {
+		t.Errorf("RegistryLocations() = %v, want none", locations)
+	}
+	if trust := reloaded.HooksTrust("github.com/networkteam/neos-components"); trust != config.HooksTrusted {
+		t.Errorf("HooksTrust() = %q, want %q", trust, config.HooksTrusted)
+	}
+
+	reloaded.SetHooksTrust("github.com/networkteam/neos-components", config.HooksAsk)
+	if trust := reloaded.HooksTrust("github.com/networkteam/neos-components"); trust != config.HooksAsk {
+		t.Errorf("HooksTrust() after reset = %q, want %q", trust, config.HooksAsk)
+	}
+}
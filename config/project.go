@@ -23,6 +23,25 @@ type ProjectConfig struct {
 	Platform string `yaml:"platform"`
 	// Variables to substitute for component templates
 	Variables map[string]any `yaml:"variables"`
+	// Variants of added components by component name
+	Variants map[string]string `yaml:"variants,omitempty"`
+}
+
+// SetVariant records the variant of an added component, an empty variant removes the record.
+// It returns true if the recorded variant changed.
+func (c *ProjectConfig) SetVariant(component, variant string) bool {
+	if c.Variants[component] == variant {
+		return false
+	}
+	if variant == "" {
+		delete(c.Variants, component)
+		return true
+	}
+	if c.Variants == nil {
+		c.Variants = make(map[string]string)
+	}
+	c.Variants[component] = variant
+	return true
 }
 
 // findNearestProjectConfigDir finds the nearest .shry.yaml file by walking up the directory tree
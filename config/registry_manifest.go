@@ -0,0 +1,132 @@
+package config
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// RegistryManifestFile is the name of the optional manifest at the root of a registry
+	RegistryManifestFile = "shry-registry.yaml"
+)
+
+// RegistryManifest describes a registry and its platforms
+type RegistryManifest struct {
+	// Name of the registry
+	Name string `yaml:"name,omitempty" json:"name,omitempty"`
+	// Optional description
+	Description string `yaml:"description,omitempty" json:"description,omitempty"`
+	// Optional maintainers, e.g. "Jane Doe <jane@example.com>"
+	Maintainers []string `yaml:"maintainers,omitempty" json:"maintainers,omitempty"`
+	// MinVersion is the minimum version of shry required to use the registry (optional)
+	MinVersion string `yaml:"minVersion,omitempty" json:"minVersion,omitempty"`
+	// Platforms by name (optional)
+	Platforms map[string]Platform `yaml:"platforms,omitempty" json:"platforms,omitempty"`
+}
+
+// Platform is the definition of a platform in a registry manifest
+type Platform struct {
+	// Optional title
+	Title string `yaml:"title,omitempty" json:"title,omitempty"`
+	// Optional description
+	Description string `yaml:"description,omitempty" json:"description,omitempty"`
+	// RequiredVariables must be defined by projects of the platform
+	RequiredVariables []string `yaml:"requiredVariables,omitempty" json:"requiredVariables,omitempty"`
+	// Variables are default project variables of the platform
+	Variables map[string]any `yaml:"variables,omitempty" json:"variables,omitempty"`
+	// Aliases are other names of the platform, e.g. used by components or projects
+	Aliases []string `yaml:"aliases,omitempty" json:"aliases,omitempty"`
+}
+
+// ParseRegistryManifest parses and validates a registry manifest
+func ParseRegistryManifest(data []byte) (*RegistryManifest, error) {
+	var manifest RegistryManifest
+	if err := yaml.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("parsing registry manifest: %w", err)
+	}
+
+	// Aliases must be unique across platforms
+	names := make(map[string]string)
+	for _, name := range manifest.PlatformNames() {
+		names[name] = name
+	}
+	for _, name := range manifest.PlatformNames() {
+		for _, alias := range manifest.Platforms[name].Aliases {
+			if other, exists := names[alias]; exists {
+				return nil, fmt.Errorf("alias %s of platform %s is already used by platform %s", alias, name, other)
+			}
+			names[alias] = name
+		}
+	}
+
+	if manifest.MinVersion != "" {
+		if _, err := parseVersion(manifest.MinVersion); err != nil {
+			return nil, fmt.Errorf("invalid minVersion: %w", err)
+		}
+	}
+
+	return &manifest, nil
+}
+
+// PlatformNames returns the names of all defined platforms sorted by name
+func (m *RegistryManifest) PlatformNames() []string {
+	return slices.Sorted(maps.Keys(m.Platforms))
+}
+
+// ResolvePlatform returns the name of the platform with the name or alias, or the given name if no platform defines it
+func (m *RegistryManifest) ResolvePlatform(name string) string {
+	if _, exists := m.Platforms[name]; exists {
+		return name
+	}
+	for platformName, platform := range m.Platforms {
+		if slices.Contains(platform.Aliases, name) {
+			return platformName
+		}
+	}
+	return name
+}
+
+// CheckVersion returns an error if the version is older than the minimum version of the registry.
+// Development builds without a release version are not checked.
+func (m *RegistryManifest) CheckVersion(version string) error {
+	if m.MinVersion == "" {
+		return nil
+	}
+	current, err := parseVersion(version)
+	if err != nil {
+		return nil
+	}
+	minimum, err := parseVersion(m.MinVersion)
+	if err != nil {
+		return fmt.Errorf("invalid minVersion: %w", err)
+	}
+
+	if slices.Compare(current, minimum) < 0 {
+		return fmt.Errorf("registry requires shry %s or newer, you are using %s", m.MinVersion, version)
+	}
+	return nil
+}
+
+// parseVersion parses the major, minor and patch numbers of a version like v1.2.3, pre-release suffixes are ignored
+func parseVersion(version string) ([]int, error) {
+	core, _, _ := strings.Cut(strings.TrimPrefix(version, "v"), "-")
+	parts := strings.Split(core, ".")
+	if len(parts) > 3 {
+		return nil, fmt.Errorf("version %s is not a semantic version", version)
+	}
+
+	numbers := make([]int, 3)
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("version %s is not a semantic version", version)
+		}
+		numbers[i] = n
+	}
+	return numbers, nil
+}
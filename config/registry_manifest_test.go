@@ -0,0 +1,88 @@
+package config_test
+
+import (
+	"testing"
+
+	"github.com/networkteam/shry/config"
+)
+
+func TestRegistryManifest(t *testing.T) {
+	manifest, err := config.ParseRegistryManifest([]byte(`name: Acme Components
+description: Components for Acme projects
+maintainers:
+  - Jane Doe <jane@example.com>
+minVersion: v1.4.0
+platforms:
+  neos:
+    description: Neos CMS 8 and 9
+    requiredVariables: [basePackageKey]
+    variables:
+      basePackagePath: DistributionPackages/Acme.Site
+    aliases: [neos-cms]
+  react:
+    aliases: [nextjs]
+`))
+	if err != nil {
+		t.Fatalf("ParseRegistryManifest() unexpected error: %v", err)
+	}
+
+	for name, expected := range map[string]string{
+		"neos":     "neos",
+		"neos-cms": "neos",
+		"nextjs":   "react",
+		"vue":      "vue",
+	} {
+		if actual := manifest.ResolvePlatform(name); actual != expected {
+			t.Errorf("ResolvePlatform(%q) = %q, want %q", name, actual, expected)
+		}
+	}
+
+	tests := []struct {
+		version     string
+		expectedErr bool
+	}{
+		{version: "v1.4.0"},
+		{version: "1.10.2"},
+		{version: "v2.0.0-rc.1"},
+		{version: "v1.3.9", expectedErr: true},
+		{version: "1.4.0-beta.1"},
+		{version: "dev"},
+	}
+	for _, tt := range tests {
+		err := manifest.CheckVersion(tt.version)
+		if tt.expectedErr && err == nil {
+			t.Errorf("CheckVersion(%q) expected error, got nil", tt.version)
+		}
+		if !tt.expectedErr && err != nil {
+			t.Errorf("CheckVersion(%q) unexpected error: %v", tt.version, err)
+		}
+	}
+}
+
+func TestParseRegistryManifestErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		manifest string
+	}{
+		{
+			name:     "alias used twice",
+			manifest: "platforms:\n  neos:\n    aliases: [cms]\n  typo3:\n    aliases: [cms]\n",
+		},
+		{
+			name:     "alias of another platform",
+			manifest: "platforms:\n  neos: {}\n  flow:\n    aliases: [neos]\n",
+		},
+		{
+			name:     "invalid minimum version",
+			manifest: "minVersion: latest\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := config.ParseRegistryManifest([]byte(tt.manifest)); err == nil {
+				t.Error("ParseRegistryManifest() expected error, got nil")
+			}
+		})
+	}
+}
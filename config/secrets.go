@@ -0,0 +1,256 @@
+package config
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+)
+
+const (
+	// SecretStoreTypeFile stores secrets in an encrypted file next to the global configuration,
+	// only protected by a passphrase from SHRY_SECRETS_PASSPHRASE (the generated key file is stored next to it)
+	SecretStoreTypeFile = "file"
+	// SecretStoreTypeCommand stores secrets with external commands (e.g. pass or the 1Password CLI)
+	SecretStoreTypeCommand = "command"
+
+	// secretRefHTTPPassword is the reference suffix for HTTP passwords
+	secretRefHTTPPassword = "http-password"
+	// secretRefHTTPToken is the reference suffix for HTTP tokens
+	secretRefHTTPToken = "http-token"
+	// secretRefSSHPassword is the reference suffix for SSH private key passwords
+	secretRefSSHPassword = "ssh-password"
+)
+
+// SecretStore stores secrets referenced from the global configuration
+type SecretStore interface {
+	// Get returns the secret for the reference
+	Get(ref string) (string, error)
+	// Set stores the secret for the reference
+	Set(ref string, value string) error
+	// Delete removes the secret for the reference, deleting a missing secret is not an error
+	Delete(ref string) error
+}
+
+// SecretStoreConfig configures where secrets of the global configuration are stored
+type SecretStoreConfig struct {
+	// Type of the secret store: "file" (default) or "command"
+	Type string `yaml:"type,omitempty"`
+	// Path of the encrypted secrets file (file store, defaults to secrets.enc next to the global config)
+	Path string `yaml:"path,omitempty"`
+	// Command to print a secret, {{ref}} is replaced with the secret reference (command store)
+	Get []string `yaml:"get,omitempty"`
+	// Command to store a secret read from stdin (command store, optional)
+	Set []string `yaml:"set,omitempty"`
+	// Command to delete a secret (command store, optional)
+	Delete []string `yaml:"delete,omitempty"`
+}
+
+// SecretStore returns the configured secret store
+func (c *GlobalConfig) SecretStore() (SecretStore, error) {
+	if c.secretStore != nil {
+		return c.secretStore, nil
+	}
+
+	storeConfig := c.Secrets
+	switch storeConfig.Type {
+	case "", SecretStoreTypeFile:
+		path := storeConfig.Path
+		if path == "" {
+			path = filepath.Join(filepath.Dir(c.ConfigPath), secretsFile)
+		}
+		c.secretStore = newFileSecretStore(path)
+	case SecretStoreTypeCommand:
+		if len(storeConfig.Get) == 0 {
+			return nil, fmt.Errorf("secret store command requires a get command")
+		}
+		c.secretStore = &commandSecretStore{
+			getCommand:    storeConfig.Get,
+			setCommand:    storeConfig.Set,
+			deleteCommand: storeConfig.Delete,
+		}
+	default:
+		return nil, fmt.Errorf("unknown secret store type %q", storeConfig.Type)
+	}
+
+	return c.secretStore, nil
+}
+
+// HasPlaintextSecrets checks if the configuration contains secrets that are not yet in the secret store
+func (c *GlobalConfig) HasPlaintextSecrets() bool {
+	for _, configs := range []map[string]RegistryConfig{c.Registries, c.Auth} {
+		for _, registryConfig := range configs {
+			if registryConfig.HTTP != nil && (registryConfig.HTTP.Password != "" || registryConfig.HTTP.Token != "") {
+				return true
+			}
+			if registryConfig.SSH != nil && registryConfig.SSH.Password != "" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// storeSecrets moves plaintext secrets into the secret store and replaces them with references
+func (c *GlobalConfig) storeSecrets() error {
+	if !c.HasPlaintextSecrets() {
+		return nil
+	}
+
+	store, err := c.SecretStore()
+	if err != nil {
+		return err
+	}
+
+	for _, configs := range []map[string]RegistryConfig{c.Registries, c.Auth} {
+		for location, registryConfig := range configs {
+			if registryConfig.HTTP != nil {
+				if err := storeSecret(store, location, secretRefHTTPPassword, &registryConfig.HTTP.Password, &registryConfig.HTTP.PasswordRef); err != nil {
+					return err
+				}
+				if err := storeSecret(store, location, secretRefHTTPToken, &registryConfig.HTTP.Token, &registryConfig.HTTP.TokenRef); err != nil {
+					return err
+				}
+			}
+			if registryConfig.SSH != nil {
+				if err := storeSecret(store, location, secretRefSSHPassword, &registryConfig.SSH.Password, &registryConfig.SSH.PasswordRef); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// storeSecret stores a plaintext value in the store, clears it and sets the reference
+func storeSecret(store SecretStore, location string, kind string, value *string, ref *string) error {
+	if *value == "" {
+		return nil
+	}
+
+	newRef := location + "/" + kind
+	if err := store.Set(newRef, *value); err != nil {
+		return fmt.Errorf("storing secret %s: %w", newRef, err)
+	}
+
+	*value = ""
+	*ref = newRef
+
+	return nil
+}
+
+// RemoveRegistry removes the registry configuration and the decision about its hooks.
+// Its secrets are deleted from the secret store when the configuration is saved.
+func (c *GlobalConfig) RemoveRegistry(location string) {
+	registryConfig, exists := c.Registries[location]
+	if !exists {
+		return
+	}
+
+	c.markSecretsStale(registryConfig)
+	delete(c.Registries, location)
+	delete(c.Hooks, location)
+}
+
+// SetAuth replaces the credentials of a configured registry or, for other locations, of a host or path prefix.
+// Secrets of the previous credentials are deleted when the configuration is saved.
+func (c *GlobalConfig) SetAuth(location string, authConfig RegistryConfig) {
+	if registryConfig, exists := c.Registries[location]; exists {
+		c.markSecretsStale(registryConfig)
+		registryConfig.HTTP = authConfig.HTTP
+		registryConfig.SSH = authConfig.SSH
+		c.Registries[location] = registryConfig
+		return
+	}
+
+	if c.Auth == nil {
+		c.Auth = make(map[string]RegistryConfig)
+	}
+	c.markSecretsStale(c.Auth[location])
+	c.Auth[location] = authConfig
+}
+
+// RemoveAuth removes the credentials of a registry, host or path prefix, a registry stays configured.
+// Their secrets are deleted from the secret store when the configuration is saved.
+func (c *GlobalConfig) RemoveAuth(location string) {
+	if authConfig, exists := c.Auth[location]; exists {
+		c.markSecretsStale(authConfig)
+		delete(c.Auth, location)
+	}
+
+	if registryConfig, exists := c.Registries[location]; exists {
+		c.markSecretsStale(registryConfig)
+		registryConfig.HTTP = nil
+		registryConfig.SSH = nil
+		c.Registries[location] = registryConfig
+	}
+}
+
+// markSecretsStale remembers the secrets referenced by the config for deletion on save
+func (c *GlobalConfig) markSecretsStale(registryConfig RegistryConfig) {
+	c.staleSecretRefs = append(c.staleSecretRefs, secretRefs(registryConfig)...)
+}
+
+// deleteStaleSecrets deletes secrets of removed or replaced credentials that are no longer referenced.
+// It is called after the configuration was written, so a failed save never loses existing secrets.
+func (c *GlobalConfig) deleteStaleSecrets() error {
+	if len(c.staleSecretRefs) == 0 {
+		return nil
+	}
+
+	inUse := make(map[string]bool)
+	for _, configs := range []map[string]RegistryConfig{c.Registries, c.Auth} {
+		for _, registryConfig := range configs {
+			for _, ref := range secretRefs(registryConfig) {
+				inUse[ref] = true
+			}
+		}
+	}
+
+	for _, ref := range c.staleSecretRefs {
+		if inUse[ref] {
+			continue
+		}
+		store, err := c.SecretStore()
+		if err != nil {
+			return err
+		}
+		if err := store.Delete(ref); err != nil {
+			return fmt.Errorf("deleting secret %s: %w", ref, err)
+		}
+	}
+	c.staleSecretRefs = nil
+
+	return nil
+}
+
+// secretRefs returns the secret references of the config
+func secretRefs(registryConfig RegistryConfig) []string {
+	var refs []string
+	if registryConfig.HTTP != nil {
+		refs = append(refs, registryConfig.HTTP.PasswordRef, registryConfig.HTTP.TokenRef)
+	}
+	if registryConfig.SSH != nil {
+		refs = append(refs, registryConfig.SSH.PasswordRef)
+	}
+	return slices.DeleteFunc(refs, func(ref string) bool { return ref == "" })
+}
+
+// resolveSecret returns the plaintext value or the referenced secret from the store
+func (c *GlobalConfig) resolveSecret(value string, ref string) (string, error) {
+	if value != "" || ref == "" {
+		return value, nil
+	}
+
+	store, err := c.SecretStore()
+	if err != nil {
+		return "", err
+	}
+
+	secret, err := store.Get(ref)
+	if err != nil {
+		return "", fmt.Errorf("getting secret %s: %w", ref, err)
+	}
+
+	return secret, nil
+}
@@ -0,0 +1,74 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/networkteam/shry/template"
+)
+
+// commandSecretStore stores secrets with external commands like pass or the 1Password CLI.
+// Each command is a list of arguments, where {{ref}} is replaced with the secret reference.
+type commandSecretStore struct {
+	getCommand    []string
+	setCommand    []string
+	deleteCommand []string
+}
+
+// Get runs the get command and returns its output without the trailing newline
+func (s *commandSecretStore) Get(ref string) (string, error) {
+	output, err := runSecretCommand(s.getCommand, ref, nil)
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimRight(string(output), "\r\n"), nil
+}
+
+// Set runs the set command with the secret on stdin
+func (s *commandSecretStore) Set(ref string, value string) error {
+	if len(s.setCommand) == 0 {
+		return fmt.Errorf("secret store has no set command, store the secret %s manually", ref)
+	}
+
+	_, err := runSecretCommand(s.setCommand, ref, strings.NewReader(value+"\n"))
+	return err
+}
+
+// Delete runs the delete command, if configured
+func (s *commandSecretStore) Delete(ref string) error {
+	if len(s.deleteCommand) == 0 {
+		return nil
+	}
+
+	_, err := runSecretCommand(s.deleteCommand, ref, nil)
+	return err
+}
+
+// runSecretCommand runs the command with the reference substituted in all arguments
+func runSecretCommand(command []string, ref string, stdin *strings.Reader) ([]byte, error) {
+	args := make([]string, len(command))
+	for i, arg := range command {
+		resolved, err := template.Resolve(arg, map[string]any{"ref": ref})
+		if err != nil {
+			return nil, fmt.Errorf("resolving secret command: %w", err)
+		}
+		args[i] = resolved
+	}
+
+	cmd := exec.Command(args[0], args[1:]...)
+	if stdin != nil {
+		cmd.Stdin = stdin
+	}
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("running %s: %w: %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+
+	return output, nil
+}
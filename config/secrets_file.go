@@ -0,0 +1,222 @@
+package config
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"golang.org/x/crypto/scrypt"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// secretsFile is the default name of the encrypted secrets file
+	secretsFile = "secrets.enc"
+	// secretsKeyFileSuffix is appended to the secrets file path for the generated key file
+	secretsKeyFileSuffix = ".key"
+
+	// EnvSecretsPassphrase is the environment variable with a passphrase to encrypt the secrets file.
+	// If it is not set, a random key is generated and stored next to the secrets file. That only keeps
+	// secrets out of the global configuration, anyone who can read both files can decrypt the secrets.
+	EnvSecretsPassphrase = "SHRY_SECRETS_PASSPHRASE"
+)
+
+// secretsFileMagic identifies the format of the encrypted secrets file
+var secretsFileMagic = []byte("SHRYSEC1")
+
+const (
+	saltSize = 16
+	keySize  = 32
+)
+
+// fileSecretStore stores secrets in an AES-GCM encrypted YAML file.
+// Without a passphrase this is obfuscation, since the key file is stored next to it.
+type fileSecretStore struct {
+	path string
+}
+
+func newFileSecretStore(path string) *fileSecretStore {
+	return &fileSecretStore{path: path}
+}
+
+// Get returns the secret for the reference
+func (s *fileSecretStore) Get(ref string) (string, error) {
+	secrets, err := s.load()
+	if err != nil {
+		return "", err
+	}
+
+	value, exists := secrets[ref]
+	if !exists {
+		return "", fmt.Errorf("secret not found in %s", s.path)
+	}
+
+	return value, nil
+}
+
+// Set stores the secret for the reference
+func (s *fileSecretStore) Set(ref string, value string) error {
+	secrets, err := s.load()
+	if err != nil {
+		return err
+	}
+
+	secrets[ref] = value
+
+	return s.save(secrets)
+}
+
+// Delete removes the secret for the reference
+func (s *fileSecretStore) Delete(ref string) error {
+	secrets, err := s.load()
+	if err != nil {
+		return err
+	}
+
+	if _, exists := secrets[ref]; !exists {
+		return nil
+	}
+	delete(secrets, ref)
+
+	return s.save(secrets)
+}
+
+func (s *fileSecretStore) load() (map[string]string, error) {
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return make(map[string]string), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading secrets file: %w", err)
+	}
+
+	if !bytes.HasPrefix(data, secretsFileMagic) || len(data) < len(secretsFileMagic)+saltSize {
+		return nil, fmt.Errorf("invalid secrets file %s", s.path)
+	}
+	data = data[len(secretsFileMagic):]
+	salt, data := data[:saltSize], data[saltSize:]
+
+	gcm, err := s.cipher(salt, false)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(data) < gcm.NonceSize() {
+		return nil, fmt.Errorf("invalid secrets file %s", s.path)
+	}
+	nonce, ciphertext := data[:gcm.NonceSize()], data[gcm.NonceSize():]
+
+	plaintext, err := gcm.Open(nil, nonce, ciphertext, secretsFileMagic)
+	if err != nil {
+		return nil, fmt.Errorf("decrypting secrets file %s (wrong key or passphrase?): %w", s.path, err)
+	}
+
+	secrets := make(map[string]string)
+	if err := yaml.Unmarshal(plaintext, &secrets); err != nil {
+		return nil, fmt.Errorf("parsing secrets file: %w", err)
+	}
+
+	return secrets, nil
+}
+
+func (s *fileSecretStore) save(secrets map[string]string) error {
+	plaintext, err := yaml.Marshal(secrets)
+	if err != nil {
+		return fmt.Errorf("marshalling secrets: %w", err)
+	}
+
+	salt := make([]byte, saltSize)
+	if _, err := io.ReadFull(rand.Reader, salt); err != nil {
+		return fmt.Errorf("generating salt: %w", err)
+	}
+
+	gcm, err := s.cipher(salt, true)
+	if err != nil {
+		return err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return fmt.Errorf("generating nonce: %w", err)
+	}
+
+	var data bytes.Buffer
+	data.Write(secretsFileMagic)
+	data.Write(salt)
+	data.Write(nonce)
+	data.Write(gcm.Seal(nil, nonce, plaintext, secretsFileMagic))
+
+	if err := writePrivateFile(s.path, data.Bytes()); err != nil {
+		return fmt.Errorf("writing secrets file: %w", err)
+	}
+
+	return nil
+}
+
+// cipher returns the AES-GCM cipher for the secrets file.
+// The key is derived from the passphrase in the environment or read from the key file, which is generated if allowed.
+func (s *fileSecretStore) cipher(salt []byte, generateKey bool) (cipher.AEAD, error) {
+	var key []byte
+	if passphrase := os.Getenv(EnvSecretsPassphrase); passphrase != "" {
+		derived, err := scrypt.Key([]byte(passphrase), salt, 1<<15, 8, 1, keySize)
+		if err != nil {
+			return nil, fmt.Errorf("deriving key: %w", err)
+		}
+		key = derived
+	} else {
+		keyPath := s.path + secretsKeyFileSuffix
+		existing, err := os.ReadFile(keyPath)
+		switch {
+		case err == nil:
+			key = existing
+		case errors.Is(err, os.ErrNotExist) && generateKey:
+			key = make([]byte, keySize)
+			if _, err := io.ReadFull(rand.Reader, key); err != nil {
+				return nil, fmt.Errorf("generating key: %w", err)
+			}
+			if err := writePrivateFile(keyPath, key); err != nil {
+				return nil, fmt.Errorf("writing key file: %w", err)
+			}
+		default:
+			return nil, fmt.Errorf("reading key file: %w", err)
+		}
+	}
+
+	if len(key) != keySize {
+		return nil, fmt.Errorf("invalid key size %d", len(key))
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("creating cipher: %w", err)
+	}
+
+	return cipher.NewGCM(block)
+}
+
+// writePrivateFile writes a file that is only readable by the current user
+func writePrivateFile(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	// Restrict permissions of files created before with default permissions
+	if err := file.Chmod(0600); err != nil {
+		return err
+	}
+
+	_, err = file.Write(data)
+	return err
+}
@@ -0,0 +1,133 @@
+package config_test
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/go-git/go-git/v5/plumbing/transport/http"
+
+	"github.com/networkteam/shry/config"
+)
+
+func TestSaveMovesSecretsToFileStore(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), config.GlobalConfigFile)
+
+	globalConfig, err := config.LoadGlobalConfig(configPath)
+	if err != nil {
+		t.Fatalf("LoadGlobalConfig() unexpected error: %v", err)
+	}
+	globalConfig.DisableGitCredentials = true
+	globalConfig.Auth = map[string]config.RegistryConfig{
+		"gitlab.example.com": {
+			HTTP: &config.HTTPAuth{Username: "deploy", Password: "s3cret-password"},
+		},
+	}
+
+	if err := globalConfig.Save(); err != nil {
+		t.Fatalf("Save() unexpected error: %v", err)
+	}
+
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		t.Fatalf("reading config: %v", err)
+	}
+	if strings.Contains(string(data), "s3cret-password") {
+		t.Errorf("saved config contains plaintext password:\n%s", data)
+	}
+
+	info, err := os.Stat(configPath)
+	if err != nil {
+		t.Fatalf("stat config: %v", err)
+	}
+	if perm := info.Mode().Perm(); perm != 0600 && runtime.GOOS != "windows" {
+		t.Errorf("config permissions = %o, want 600", perm)
+	}
+
+	reloaded, err := config.LoadGlobalConfig(configPath)
+	if err != nil {
+		t.Fatalf("LoadGlobalConfig() unexpected error: %v", err)
+	}
+	if reloaded.HasPlaintextSecrets() {
+		t.Error("HasPlaintextSecrets() = true after save, want false")
+	}
+
+	endpoint, err := transport.NewEndpoint("https://gitlab.example.com/team/registry.git")
+	if err != nil {
+		t.Fatalf("NewEndpoint() unexpected error: %v", err)
+	}
+	auth, err := reloaded.GetAuth("gitlab.example.com/team/registry", endpoint)
+	if err != nil {
+		t.Fatalf("GetAuth() unexpected error: %v", err)
+	}
+	expected := &http.BasicAuth{Username: "deploy", Password: "s3cret-password"}
+	if !reflect.DeepEqual(auth, expected) {
+		t.Errorf("GetAuth() = %v, want %v", auth, expected)
+	}
+
+	reloaded.RemoveAuth("gitlab.example.com")
+	if _, exists := reloaded.Auth["gitlab.example.com"]; exists {
+		t.Error("RemoveAuth() did not remove the auth entry")
+	}
+}
+
+func TestSetAuthKeepsSecretsUntilSaved(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("secret store commands use sh")
+	}
+
+	dir := t.TempDir()
+	deletedLog := filepath.Join(dir, "deleted.log")
+
+	newConfig := func(setCommand []string) *config.GlobalConfig {
+		return &config.GlobalConfig{
+			ConfigPath: filepath.Join(dir, config.GlobalConfigFile),
+			Auth: map[string]config.RegistryConfig{
+				"gitlab.example.com": {
+					HTTP: &config.HTTPAuth{Username: "deploy", PasswordRef: "gitlab.example.com/http-password"},
+				},
+			},
+			Secrets: config.SecretStoreConfig{
+				Type:   config.SecretStoreTypeCommand,
+				Get:    []string{"echo", "secret"},
+				Set:    setCommand,
+				Delete: []string{"sh", "-c", "echo {{ref}} >> " + deletedLog},
+			},
+		}
+	}
+
+	// A failing store command must not delete the existing secret
+	globalConfig := newConfig([]string{"false"})
+	globalConfig.SetAuth("gitlab.example.com", config.RegistryConfig{
+		HTTP: &config.HTTPAuth{Token: "new-token"},
+	})
+	if err := globalConfig.Save(); err == nil {
+		t.Fatal("Save() expected error from failing set command")
+	}
+	if _, err := os.Stat(deletedLog); !os.IsNotExist(err) {
+		t.Errorf("secrets were deleted although saving failed")
+	}
+
+	// After a successful save the replaced secret is deleted
+	globalConfig = newConfig([]string{"sh", "-c", "cat > /dev/null"})
+	globalConfig.SetAuth("gitlab.example.com", config.RegistryConfig{
+		HTTP: &config.HTTPAuth{Token: "new-token"},
+	})
+	if err := globalConfig.Save(); err != nil {
+		t.Fatalf("Save() unexpected error: %v", err)
+	}
+	deleted, err := os.ReadFile(deletedLog)
+	if err != nil {
+		t.Fatalf("reading deleted secrets: %v", err)
+	}
+	if got := strings.TrimSpace(string(deleted)); got != "gitlab.example.com/http-password" {
+		t.Errorf("deleted secrets = %q, want gitlab.example.com/http-password", got)
+	}
+	if ref := globalConfig.Auth["gitlab.example.com"].HTTP.TokenRef; ref != "gitlab.example.com/http-token" {
+		t.Errorf("TokenRef = %q, want gitlab.example.com/http-token", ref)
+	}
+}
@@ -0,0 +1,322 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	gogitssh "github.com/go-git/go-git/v5/plumbing/transport/ssh"
+	"github.com/kevinburke/ssh_config"
+	"github.com/mitchellh/go-homedir"
+	"github.com/skeema/knownhosts"
+	sshagent "github.com/xanzy/ssh-agent"
+	"golang.org/x/crypto/ssh"
+)
+
+const (
+	// defaultSSHUser is used if no user is given by the registry config, the URL or ~/.ssh/config
+	defaultSSHUser = "git"
+)
+
+// defaultIdentityFiles are tried (like OpenSSH does) if they exist
+var defaultIdentityFiles = []string{
+	"~/.ssh/id_ed25519",
+	"~/.ssh/id_ecdsa",
+	"~/.ssh/id_rsa",
+}
+
+// ErrNoSSHIdentities is returned if neither a private key nor the SSH agent provides an identity
+var ErrNoSSHIdentities = errors.New("no SSH identities available, configure a private key or start an SSH agent")
+
+// SSHAuthError is returned when the SSH server rejected all offered identities
+type SSHAuthError struct {
+	// User used for authentication
+	User string
+}
+
+func (e *SSHAuthError) Error() string {
+	return fmt.Sprintf("ssh: server rejected all identities offered for user %s", e.User)
+}
+
+// UnknownHostKeyError is returned when the host key of an SSH server is not found in any known_hosts file
+type UnknownHostKeyError struct {
+	// Hostname (with port) as used for the connection
+	Hostname string
+	// Key presented by the server
+	Key ssh.PublicKey
+}
+
+func (e *UnknownHostKeyError) Error() string {
+	return fmt.Sprintf("host key for %s is unknown (%s %s)", e.Hostname, e.Key.Type(), e.Fingerprint())
+}
+
+// Fingerprint returns the SHA256 fingerprint of the host key
+func (e *UnknownHostKeyError) Fingerprint() string {
+	return ssh.FingerprintSHA256(e.Key)
+}
+
+// TrustHostKey adds the host key of the error to the user's known_hosts file
+func TrustHostKey(e *UnknownHostKeyError) error {
+	files, err := knownHostsFiles()
+	if err != nil {
+		return err
+	}
+	path := files[0]
+
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("creating known_hosts directory: %w", err)
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("opening known_hosts: %w", err)
+	}
+	defer file.Close()
+
+	if _, err := fmt.Fprintln(file, knownhosts.Line([]string{e.Hostname}, e.Key)); err != nil {
+		return fmt.Errorf("writing known_hosts: %w", err)
+	}
+
+	return nil
+}
+
+// sshAuth builds the SSH authentication method for the given endpoint.
+// Settings from the registry config take precedence over ~/.ssh/config.
+func sshAuth(endpoint *transport.Endpoint, sshConfig *SSHAuth) (transport.AuthMethod, error) {
+	alias := endpoint.Host
+
+	var configuredUser, password string
+	explicitKey := false
+	if sshConfig != nil {
+		configuredUser = sshConfig.User
+		password = sshConfig.Password
+		explicitKey = sshConfig.PrivateKeyPath != ""
+	}
+
+	user := firstNonEmpty(
+		configuredUser,
+		endpoint.User,
+		ssh_config.Get(alias, "User"),
+		defaultSSHUser,
+	)
+
+	// An explicitly configured key is used exclusively, otherwise identities from
+	// ~/.ssh/config, the default identity files and the SSH agent are offered
+	var keyFiles []string
+	if explicitKey {
+		keyFiles = []string{sshConfig.PrivateKeyPath}
+	} else {
+		keyFiles = append(ssh_config.GetAll(alias, "IdentityFile"), defaultIdentityFiles...)
+	}
+
+	hostWithPort := sshHostWithPort(endpoint)
+	hostKeyCallback, hostKeyAlgorithms, err := hostKeyVerification(hostWithPort)
+	if err != nil {
+		return nil, err
+	}
+
+	return &publicKeysAuth{
+		User: user,
+		Callback: func() ([]ssh.Signer, error) {
+			return loadSigners(keyFiles, password, !explicitKey, explicitKey)
+		},
+		HostKeyCallbackHelper: gogitssh.HostKeyCallbackHelper{
+			HostKeyCallback:   hostKeyCallback,
+			HostKeyAlgorithms: hostKeyAlgorithms,
+		},
+	}, nil
+}
+
+// publicKeysAuth is like go-git's PublicKeysCallback, but fails with an SSHAuthError
+// instead of an untyped error if the server rejected all offered identities
+type publicKeysAuth struct {
+	User     string
+	Callback func() ([]ssh.Signer, error)
+	gogitssh.HostKeyCallbackHelper
+}
+
+func (a *publicKeysAuth) Name() string {
+	return gogitssh.PublicKeysCallbackName
+}
+
+func (a *publicKeysAuth) String() string {
+	return fmt.Sprintf("user: %s, name: %s", a.User, a.Name())
+}
+
+func (a *publicKeysAuth) ClientConfig() (*ssh.ClientConfig, error) {
+	// The public key method is only retried if all signers of the first attempt were rejected,
+	// so the second invocation of the callback reports the failure
+	attempted := false
+	callback := func() ([]ssh.Signer, error) {
+		if attempted {
+			return nil, &SSHAuthError{User: a.User}
+		}
+		attempted = true
+		return a.Callback()
+	}
+
+	return a.SetHostKeyCallback(&ssh.ClientConfig{
+		User: a.User,
+		Auth: []ssh.AuthMethod{ssh.RetryableAuthMethod(ssh.PublicKeysCallback(callback), 2)},
+	})
+}
+
+// loadSigners collects signers from the given key files and the SSH agent.
+// If strict is set, a key file that cannot be loaded is an error, otherwise it is skipped.
+func loadSigners(keyFiles []string, password string, useAgent bool, strict bool) ([]ssh.Signer, error) {
+	var signers []ssh.Signer
+	seen := make(map[string]bool)
+
+	for _, keyFile := range keyFiles {
+		path, err := homedir.Expand(keyFile)
+		if err != nil || seen[path] {
+			continue
+		}
+		seen[path] = true
+
+		signer, err := loadSigner(path, password)
+		if err != nil {
+			if strict {
+				return nil, err
+			}
+			if !errors.Is(err, os.ErrNotExist) {
+				slog.Debug("Skipping SSH identity", "path", path, "error", err)
+			}
+			continue
+		}
+		signers = append(signers, signer)
+	}
+
+	if useAgent && sshagent.Available() {
+		agent, _, err := sshagent.New()
+		if err != nil {
+			slog.Debug("Skipping SSH agent", "error", err)
+		} else {
+			agentSigners, err := agent.Signers()
+			if err != nil {
+				return nil, fmt.Errorf("getting signers from SSH agent: %w", err)
+			}
+			signers = append(signers, agentSigners...)
+		}
+	}
+
+	if len(signers) == 0 {
+		return nil, ErrNoSSHIdentities
+	}
+
+	return signers, nil
+}
+
+// loadSigner reads a private key file, using the password if the key is encrypted
+func loadSigner(path string, password string) (ssh.Signer, error) {
+	key, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading private key: %w", err)
+	}
+
+	signer, err := ssh.ParsePrivateKey(key)
+	var passphraseErr *ssh.PassphraseMissingError
+	if errors.As(err, &passphraseErr) {
+		if password == "" {
+			return nil, fmt.Errorf("private key %s is encrypted and no password is configured", path)
+		}
+		signer, err = ssh.ParsePrivateKeyWithPassphrase(key, []byte(password))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("parsing private key %s: %w", path, err)
+	}
+
+	return signer, nil
+}
+
+// hostKeyVerification returns a host key callback verifying against the known_hosts files
+// and the host key algorithms to negotiate for the given host
+func hostKeyVerification(hostWithPort string) (ssh.HostKeyCallback, []string, error) {
+	files, err := knownHostsFiles()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var existingFiles []string
+	for _, file := range files {
+		if _, err := os.Stat(file); err == nil {
+			existingFiles = append(existingFiles, file)
+		}
+	}
+
+	if len(existingFiles) == 0 {
+		return func(hostname string, remote net.Addr, key ssh.PublicKey) error {
+			return &UnknownHostKeyError{Hostname: hostname, Key: key}
+		}, nil, nil
+	}
+
+	db, err := knownhosts.NewDB(existingFiles...)
+	if err != nil {
+		return nil, nil, fmt.Errorf("reading known_hosts: %w", err)
+	}
+
+	dbCallback := db.HostKeyCallback()
+	callback := func(hostname string, remote net.Addr, key ssh.PublicKey) error {
+		err := dbCallback(hostname, remote, key)
+		if knownhosts.IsHostUnknown(err) {
+			return &UnknownHostKeyError{Hostname: hostname, Key: key}
+		}
+		if knownhosts.IsHostKeyChanged(err) {
+			return fmt.Errorf("host key for %s has changed (%s %s), this could be a man-in-the-middle attack: %w", hostname, key.Type(), ssh.FingerprintSHA256(key), err)
+		}
+		return err
+	}
+
+	return callback, db.HostKeyAlgorithms(hostWithPort), nil
+}
+
+// knownHostsFiles returns the known_hosts files to use, the first one is the user's file.
+// Like go-git, the SSH_KNOWN_HOSTS environment variable can override the default files.
+func knownHostsFiles() ([]string, error) {
+	if env := os.Getenv("SSH_KNOWN_HOSTS"); env != "" {
+		return filepath.SplitList(env), nil
+	}
+
+	home, err := homedir.Dir()
+	if err != nil {
+		return nil, fmt.Errorf("getting home directory: %w", err)
+	}
+
+	return []string{
+		filepath.Join(home, ".ssh", "known_hosts"),
+		"/etc/ssh/ssh_known_hosts",
+	}, nil
+}
+
+// sshHostWithPort resolves the address to connect to, respecting Host aliases in ~/.ssh/config
+func sshHostWithPort(endpoint *transport.Endpoint) string {
+	host := endpoint.Host
+	port := endpoint.Port
+
+	if hostname := ssh_config.Get(endpoint.Host, "Hostname"); hostname != "" {
+		host = hostname
+		if configPort, err := strconv.Atoi(ssh_config.Get(endpoint.Host, "Port")); err == nil {
+			port = configPort
+		}
+	}
+	if port <= 0 {
+		port = gogitssh.DefaultPort
+	}
+
+	return net.JoinHostPort(host, strconv.Itoa(port))
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if strings.TrimSpace(v) != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,297 @@
+package config_test
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/pem"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	gogitssh "github.com/go-git/go-git/v5/plumbing/transport/ssh"
+	"golang.org/x/crypto/ssh"
+
+	"github.com/networkteam/shry/config"
+)
+
+const sshLocation = "ssh://git@registry.example.com/team/registry.git"
+
+func TestSSHHostKeyVerification(t *testing.T) {
+	knownHostsPath := filepath.Join(t.TempDir(), "known_hosts")
+	t.Setenv("SSH_KNOWN_HOSTS", knownHostsPath)
+
+	hostKey := generateSigner(t).PublicKey()
+	otherHostKey := generateSigner(t).PublicKey()
+	keyFile := writePrivateKey(t, "")
+	remote := &net.TCPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 22}
+
+	// Without a known_hosts file every host is unknown
+	err := sshClientConfig(t, keyFile, "").HostKeyCallback("registry.example.com:22", remote, hostKey)
+	var hostKeyErr *config.UnknownHostKeyError
+	if !errors.As(err, &hostKeyErr) {
+		t.Fatalf("HostKeyCallback() error = %v, want UnknownHostKeyError", err)
+	}
+	if hostKeyErr.Fingerprint() != ssh.FingerprintSHA256(hostKey) {
+		t.Errorf("Fingerprint() = %s, want %s", hostKeyErr.Fingerprint(), ssh.FingerprintSHA256(hostKey))
+	}
+
+	if err := config.TrustHostKey(hostKeyErr); err != nil {
+		t.Fatalf("TrustHostKey() unexpected error: %v", err)
+	}
+	if _, err := os.Stat(knownHostsPath); err != nil {
+		t.Fatalf("known_hosts not written: %v", err)
+	}
+
+	// The trusted key is accepted
+	callback := sshClientConfig(t, keyFile, "").HostKeyCallback
+	if err := callback("registry.example.com:22", remote, hostKey); err != nil {
+		t.Errorf("HostKeyCallback() for trusted key unexpected error: %v", err)
+	}
+
+	// A changed key is rejected and must not be offered for trust again
+	err = callback("registry.example.com:22", remote, otherHostKey)
+	if err == nil {
+		t.Fatal("HostKeyCallback() for changed key expected error")
+	}
+	if errors.As(err, &hostKeyErr) {
+		t.Errorf("HostKeyCallback() for changed key returned UnknownHostKeyError: %v", err)
+	}
+
+	// Other hosts are still unknown
+	err = callback("other.example.com:22", remote, hostKey)
+	if !errors.As(err, &hostKeyErr) {
+		t.Errorf("HostKeyCallback() for other host error = %v, want UnknownHostKeyError", err)
+	}
+}
+
+func TestSSHAuthentication(t *testing.T) {
+	t.Setenv("SSH_KNOWN_HOSTS", filepath.Join(t.TempDir(), "known_hosts"))
+
+	tests := []struct {
+		name         string
+		keyPassword  string
+		password     string
+		authorized   bool
+		wantAuthErr  bool
+		wantDialFail bool
+	}{
+		{
+			name:       "authorized key",
+			authorized: true,
+		},
+		{
+			name:        "rejected key",
+			authorized:  false,
+			wantAuthErr: true,
+		},
+		{
+			name:        "encrypted key with password",
+			keyPassword: "secret",
+			password:    "secret",
+			authorized:  true,
+		},
+		{
+			name:         "encrypted key without password",
+			keyPassword:  "secret",
+			authorized:   true,
+			wantDialFail: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keyFile := writePrivateKey(t, tt.keyPassword)
+
+			var authorizedKey ssh.PublicKey
+			if tt.authorized {
+				authorizedKey = readPublicKey(t, keyFile, tt.keyPassword)
+			}
+			addr := startSSHServer(t, authorizedKey)
+
+			clientConfig := sshClientConfig(t, keyFile, tt.password)
+			clientConfig.HostKeyCallback = ssh.InsecureIgnoreHostKey()
+
+			client, err := ssh.Dial("tcp", addr, clientConfig)
+			if client != nil {
+				client.Close()
+			}
+
+			var authErr *config.SSHAuthError
+			switch {
+			case tt.wantAuthErr:
+				if !errors.As(err, &authErr) {
+					t.Errorf("Dial() error = %v, want SSHAuthError", err)
+				}
+			case tt.wantDialFail:
+				if err == nil {
+					t.Error("Dial() expected error")
+				} else if errors.As(err, &authErr) {
+					t.Errorf("Dial() error = %v, want error loading the key", err)
+				}
+			case err != nil:
+				t.Errorf("Dial() unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestSSHNoIdentities(t *testing.T) {
+	t.Setenv("SSH_KNOWN_HOSTS", filepath.Join(t.TempDir(), "known_hosts"))
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("SSH_AUTH_SOCK", "")
+
+	endpoint, err := transport.NewEndpoint("ssh://git@registry.example.com:2222/team/registry.git")
+	if err != nil {
+		t.Fatalf("NewEndpoint() unexpected error: %v", err)
+	}
+	globalConfig := &config.GlobalConfig{}
+	auth, err := globalConfig.GetAuth("ssh://git@registry.example.com:2222/team/registry.git", endpoint)
+	if err != nil {
+		t.Fatalf("GetAuth() unexpected error: %v", err)
+	}
+	clientConfig, err := auth.(gogitssh.AuthMethod).ClientConfig()
+	if err != nil {
+		t.Fatalf("ClientConfig() unexpected error: %v", err)
+	}
+	clientConfig.HostKeyCallback = ssh.InsecureIgnoreHostKey()
+
+	addr := startSSHServer(t, nil)
+	_, err = ssh.Dial("tcp", addr, clientConfig)
+	if !errors.Is(err, config.ErrNoSSHIdentities) {
+		t.Errorf("Dial() error = %v, want ErrNoSSHIdentities", err)
+	}
+}
+
+// sshClientConfig returns the SSH client config for a registry using the given private key
+func sshClientConfig(t *testing.T, keyFile string, password string) *ssh.ClientConfig {
+	t.Helper()
+
+	endpoint, err := transport.NewEndpoint(sshLocation)
+	if err != nil {
+		t.Fatalf("NewEndpoint() unexpected error: %v", err)
+	}
+
+	globalConfig := &config.GlobalConfig{
+		Registries: map[string]config.RegistryConfig{
+			sshLocation: {
+				SSH: &config.SSHAuth{PrivateKeyPath: keyFile, Password: password},
+			},
+		},
+	}
+	auth, err := globalConfig.GetAuth(sshLocation, endpoint)
+	if err != nil {
+		t.Fatalf("GetAuth() unexpected error: %v", err)
+	}
+
+	sshAuth, ok := auth.(gogitssh.AuthMethod)
+	if !ok {
+		t.Fatalf("GetAuth() = %T, want SSH auth method", auth)
+	}
+	clientConfig, err := sshAuth.ClientConfig()
+	if err != nil {
+		t.Fatalf("ClientConfig() unexpected error: %v", err)
+	}
+	return clientConfig
+}
+
+// startSSHServer starts an SSH server accepting only the given public key and returns its address
+func startSSHServer(t *testing.T, authorizedKey ssh.PublicKey) string {
+	t.Helper()
+
+	serverConfig := &ssh.ServerConfig{
+		PublicKeyCallback: func(conn ssh.ConnMetadata, key ssh.PublicKey) (*ssh.Permissions, error) {
+			if authorizedKey != nil && string(key.Marshal()) == string(authorizedKey.Marshal()) {
+				return nil, nil
+			}
+			return nil, errors.New("unauthorized key")
+		},
+	}
+	serverConfig.AddHostKey(generateSigner(t))
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen() unexpected error: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				serverConn, _, _, err := ssh.NewServerConn(conn, serverConfig)
+				if err == nil {
+					serverConn.Close()
+				}
+			}()
+		}
+	}()
+
+	return listener.Addr().String()
+}
+
+func generateSigner(t *testing.T) ssh.Signer {
+	t.Helper()
+
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey() unexpected error: %v", err)
+	}
+	signer, err := ssh.NewSignerFromKey(key)
+	if err != nil {
+		t.Fatalf("NewSignerFromKey() unexpected error: %v", err)
+	}
+	return signer
+}
+
+// writePrivateKey writes a new OpenSSH private key, encrypted if a password is given, and returns its path
+func writePrivateKey(t *testing.T, password string) string {
+	t.Helper()
+
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey() unexpected error: %v", err)
+	}
+
+	var block *pem.Block
+	if password != "" {
+		block, err = ssh.MarshalPrivateKeyWithPassphrase(key, "", []byte(password))
+	} else {
+		block, err = ssh.MarshalPrivateKey(key, "")
+	}
+	if err != nil {
+		t.Fatalf("MarshalPrivateKey() unexpected error: %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "id_ed25519")
+	if err := os.WriteFile(path, pem.EncodeToMemory(block), 0600); err != nil {
+		t.Fatalf("WriteFile() unexpected error: %v", err)
+	}
+	return path
+}
+
+func readPublicKey(t *testing.T, keyFile string, password string) ssh.PublicKey {
+	t.Helper()
+
+	data, err := os.ReadFile(keyFile)
+	if err != nil {
+		t.Fatalf("ReadFile() unexpected error: %v", err)
+	}
+
+	var signer ssh.Signer
+	if password != "" {
+		signer, err = ssh.ParsePrivateKeyWithPassphrase(data, []byte(password))
+	} else {
+		signer, err = ssh.ParsePrivateKey(data)
+	}
+	if err != nil {
+		t.Fatalf("ParsePrivateKey() unexpected error: %v", err)
+	}
+	return signer.PublicKey()
+}
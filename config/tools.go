@@ -0,0 +1,20 @@
+package config
+
+import "os"
+
+const (
+	// EnvDiffTool overrides the diff tool of the global configuration
+	EnvDiffTool = "SHRY_DIFFTOOL"
+	// EnvMergeTool overrides the merge tool of the global configuration
+	EnvMergeTool = "SHRY_MERGETOOL"
+)
+
+// DiffToolCommand returns the shell command of the external diff tool, empty if none is configured
+func (c *GlobalConfig) DiffToolCommand() string {
+	return firstNonEmpty(os.Getenv(EnvDiffTool), c.DiffTool)
+}
+
+// MergeToolCommand returns the shell command of the external merge tool, empty if none is configured
+func (c *GlobalConfig) MergeToolCommand() string {
+	return firstNonEmpty(os.Getenv(EnvMergeTool), c.MergeTool)
+}
@@ -2,7 +2,6 @@ package diff
 
 import (
 	"fmt"
-	"strings"
 
 	"github.com/charmbracelet/lipgloss"
 	"github.com/networkteam/shry/ui"
@@ -10,11 +9,10 @@ import (
 )
 
 func PrettyPrint(diffs []diffmatchpatch.Diff) {
-	content := prettify(diffs)
-	err := ShowDiff(content)
+	err := ShowDiff(diffs)
 	if err != nil {
 		fmt.Printf("Error displaying diff: %v\n", err)
-		fmt.Println(content)
+		fmt.Println(Render(diffs, DefaultRenderOptions()))
 	}
 }
 
@@ -51,31 +49,6 @@ func renderLine(op diffmatchpatch.Operation, text string) string {
 	}
 }
 
-func prettify(hunks []diffmatchpatch.Diff) string {
-	var result []string
-	for hunkIdx, hunk := range hunks {
-		lines := strings.Split(hunk.Text, "\n")
-
-		// remove last element if it is empty due to trailing linebreak
-		if lines[len(lines)-1] == "" {
-			lines = lines[:len(lines)-1]
-		}
-		nLines := len(lines)
-
-		if hunk.Type == diffmatchpatch.DiffEqual && nLines > contextLines*2+1 {
-			lines = compressHunk(lines, hunk.Type, hunkIdx == 0, hunkIdx == len(hunks)-1)
-		} else {
-			for i, line := range lines {
-				lines[i] = renderLine(hunk.Type, line)
-			}
-		}
-
-		result = append(result, lines...)
-	}
-
-	return strings.Join(result, "\n")
-}
-
 func compressHunk(lines []string, op diffmatchpatch.Operation, isFirst bool, isLast bool) []string {
 	var compressedLines []string
 	nLines := len(lines)
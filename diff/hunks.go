@@ -0,0 +1,252 @@
+package diff
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/sergi/go-diff/diffmatchpatch"
+
+	"github.com/networkteam/shry/ui"
+)
+
+// hunkDecision is the decision of the user for a hunk
+type hunkDecision int
+
+const (
+	hunkPending hunkDecision = iota
+	hunkAccepted
+	hunkRejected
+)
+
+var (
+	hunkHeaderStyle        = lipgloss.NewStyle().Foreground(ui.CyanColor)
+	currentHunkHeaderStyle = ui.SelectedStyle
+)
+
+type hunksModel struct {
+	title     string
+	segments  []Segment
+	hunks     []*Hunk
+	decisions map[*Hunk]hunkDecision
+	current   int
+
+	viewport viewport.Model
+	ready    bool
+	// hunkOffsets are the line offsets of the hunk headers in the rendered content
+	hunkOffsets []int
+
+	confirmed bool
+}
+
+func newHunksModel(title string, segments []Segment) hunksModel {
+	return hunksModel{
+		title:     title,
+		segments:  segments,
+		hunks:     Hunks(segments),
+		decisions: make(map[*Hunk]hunkDecision),
+		viewport:  viewport.New(0, 0),
+	}
+}
+
+func (m hunksModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m hunksModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	var cmd tea.Cmd
+
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "q", "ctrl+c", "esc":
+			return m, tea.Quit
+		case "enter":
+			m.confirmed = true
+			return m, tea.Quit
+		case "y":
+			m.decide(hunkAccepted)
+			return m, nil
+		case "n":
+			m.decide(hunkRejected)
+			return m, nil
+		case "a", "r":
+			decision := hunkAccepted
+			if msg.String() == "r" {
+				decision = hunkRejected
+			}
+			for _, hunk := range m.hunks {
+				m.decisions[hunk] = decision
+			}
+			m.refresh()
+			return m, nil
+		case "tab":
+			m.moveTo(m.current + 1)
+			return m, nil
+		case "shift+tab":
+			m.moveTo(m.current - 1)
+			return m, nil
+		}
+
+	case tea.WindowSizeMsg:
+		headerHeight := 3
+		footerHeight := 3
+		verticalMarginHeight := headerHeight + footerHeight
+
+		if !m.ready {
+			m.viewport = viewport.New(msg.Width, msg.Height-verticalMarginHeight)
+			m.viewport.YPosition = headerHeight
+			m.ready = true
+			m.refresh()
+			m.moveTo(m.current)
+		} else {
+			m.viewport.Width = msg.Width
+			m.viewport.Height = msg.Height - verticalMarginHeight
+		}
+	}
+
+	m.viewport, cmd = m.viewport.Update(msg)
+	return m, cmd
+}
+
+// decide sets the decision of the current hunk and moves to the next pending hunk
+func (m *hunksModel) decide(decision hunkDecision) {
+	if len(m.hunks) == 0 {
+		return
+	}
+	m.decisions[m.hunks[m.current]] = decision
+
+	for i := m.current + 1; i < len(m.hunks); i++ {
+		if m.decisions[m.hunks[i]] == hunkPending {
+			m.moveTo(i)
+			return
+		}
+	}
+	m.refresh()
+}
+
+// moveTo highlights the hunk with the given index and scrolls to it
+func (m *hunksModel) moveTo(index int) {
+	if index < 0 || index >= len(m.hunks) {
+		return
+	}
+	m.current = index
+	m.refresh()
+	m.viewport.SetYOffset(max(0, m.hunkOffsets[index]-contextLines-1))
+}
+
+// refresh renders the content with the current decisions
+func (m *hunksModel) refresh() {
+	var lines []string
+	m.hunkOffsets = m.hunkOffsets[:0]
+
+	hunkIdx := 0
+	for segmentIdx, segment := range m.segments {
+		if segment.Hunk == nil {
+			segmentLines := splitLines(segment.Equal)
+			if len(segmentLines) > contextLines*2+1 {
+				lines = append(lines, compressHunk(segmentLines, diffmatchpatch.DiffEqual, segmentIdx == 0, segmentIdx == len(m.segments)-1)...)
+				continue
+			}
+			for _, line := range segmentLines {
+				lines = append(lines, renderLine(diffmatchpatch.DiffEqual, line))
+			}
+			continue
+		}
+
+		m.hunkOffsets = append(m.hunkOffsets, len(lines))
+		lines = append(lines, m.renderHunkHeader(hunkIdx))
+		for _, line := range splitLines(segment.Hunk.Deleted) {
+			lines = append(lines, renderLine(diffmatchpatch.DiffDelete, line))
+		}
+		for _, line := range splitLines(segment.Hunk.Inserted) {
+			lines = append(lines, renderLine(diffmatchpatch.DiffInsert, line))
+		}
+		hunkIdx++
+	}
+
+	m.viewport.SetContent(strings.Join(lines, "\n"))
+}
+
+// renderHunkHeader renders the header line of a hunk with its decision
+func (m hunksModel) renderHunkHeader(index int) string {
+	var status string
+	switch m.decisions[m.hunks[index]] {
+	case hunkAccepted:
+		status = "✓ take new"
+	case hunkRejected:
+		status = "✗ keep existing"
+	default:
+		status = "? pending"
+	}
+
+	header := fmt.Sprintf("@@ change %d/%d: %s @@", index+1, len(m.hunks), status)
+	if index == m.current {
+		return currentHunkHeaderStyle.Render(header)
+	}
+	return hunkHeaderStyle.Render(header)
+}
+
+func (m hunksModel) View() string {
+	if !m.ready {
+		return "\n  Initializing..."
+	}
+
+	headerStyle := ui.TitleStyle.
+		Foreground(ui.SecondaryColor).
+		Background(ui.PrimaryColor).
+		Padding(0, 1)
+
+	header := headerStyle.Render(m.title)
+	footer := ui.HelpStyle.Render("y: take new • n: keep existing • a/r: take/keep all • tab/shift+tab: next/previous change • ↑/↓: scroll • enter: write (pending keeps existing) • q/esc: cancel")
+
+	return lipgloss.JoinVertical(lipgloss.Left,
+		header,
+		"",
+		m.viewport.View(),
+		"",
+		footer,
+	)
+}
+
+// ResolveHunks lets the user step through the changes from the existing to the new text and take or keep each one.
+// It returns the merged text and false if the user cancelled. Pending changes keep the existing text.
+func ResolveHunks(title string, existing, updated string) (string, bool, error) {
+	segments := Segments(LineDiff(existing, updated))
+	m := newHunksModel(title, segments)
+
+	p := tea.NewProgram(m,
+		tea.WithAltScreen(),
+		tea.WithMouseCellMotion(),
+	)
+	result, err := p.Run()
+	if err != nil {
+		return "", false, fmt.Errorf("running hunk viewer: %w", err)
+	}
+
+	finalModel := result.(hunksModel)
+	if !finalModel.confirmed {
+		return "", false, nil
+	}
+
+	accepted := make(map[*Hunk]bool)
+	for hunk, decision := range finalModel.decisions {
+		accepted[hunk] = decision == hunkAccepted
+	}
+
+	return Merge(segments, accepted), true, nil
+}
+
+// splitLines splits text into lines without a trailing empty line
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	lines := strings.Split(text, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
@@ -0,0 +1,88 @@
+package diff
+
+import (
+	"strings"
+
+	"github.com/sergi/go-diff/diffmatchpatch"
+)
+
+// LineDiff computes a line based diff from old to new text
+func LineDiff(oldText, newText string) []diffmatchpatch.Diff {
+	dmp := diffmatchpatch.New()
+	lineText1, lineText2, lineArray := dmp.DiffLinesToChars(oldText, newText)
+	diffs := dmp.DiffMain(lineText1, lineText2, false)
+	return dmp.DiffCharsToLines(diffs, lineArray)
+}
+
+// Segment is a part of a line diff, either unchanged text or a hunk of changes
+type Segment struct {
+	// Equal is the unchanged text, only set if the segment is no hunk
+	Equal string
+	// Hunk is set if the segment contains changes
+	Hunk *Hunk
+}
+
+// Hunk is a consecutive change between old and new text
+type Hunk struct {
+	// Deleted text of the old text
+	Deleted string
+	// Inserted text of the new text
+	Inserted string
+}
+
+// Segments groups a line diff into unchanged segments and hunks of consecutive changes
+func Segments(diffs []diffmatchpatch.Diff) []Segment {
+	var segments []Segment
+	var hunk *Hunk
+	for _, d := range diffs {
+		if d.Type == diffmatchpatch.DiffEqual {
+			if hunk != nil {
+				segments = append(segments, Segment{Hunk: hunk})
+				hunk = nil
+			}
+			segments = append(segments, Segment{Equal: d.Text})
+			continue
+		}
+
+		if hunk == nil {
+			hunk = &Hunk{}
+		}
+		if d.Type == diffmatchpatch.DiffDelete {
+			hunk.Deleted += d.Text
+		} else {
+			hunk.Inserted += d.Text
+		}
+	}
+	if hunk != nil {
+		segments = append(segments, Segment{Hunk: hunk})
+	}
+
+	return segments
+}
+
+// Hunks returns the hunks of the segments in order
+func Hunks(segments []Segment) []*Hunk {
+	var hunks []*Hunk
+	for _, segment := range segments {
+		if segment.Hunk != nil {
+			hunks = append(hunks, segment.Hunk)
+		}
+	}
+	return hunks
+}
+
+// Merge builds the text of the segments, taking the inserted text of accepted hunks and the deleted text of all others
+func Merge(segments []Segment, accepted map[*Hunk]bool) string {
+	var sb strings.Builder
+	for _, segment := range segments {
+		switch {
+		case segment.Hunk == nil:
+			sb.WriteString(segment.Equal)
+		case accepted[segment.Hunk]:
+			sb.WriteString(segment.Hunk.Inserted)
+		default:
+			sb.WriteString(segment.Hunk.Deleted)
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,76 @@
+package diff_test
+
+import (
+	"testing"
+
+	"github.com/networkteam/shry/diff"
+)
+
+func TestMerge(t *testing.T) {
+	tests := []struct {
+		name     string
+		existing string
+		updated  string
+		// accept are the indices of accepted hunks
+		accept    []int
+		wantHunks int
+		want      string
+	}{
+		{
+			name:      "no changes",
+			existing:  "a\nb\n",
+			updated:   "a\nb\n",
+			wantHunks: 0,
+			want:      "a\nb\n",
+		},
+		{
+			name:      "reject all keeps existing",
+			existing:  "a\nb\nc\nd\n",
+			updated:   "a\nB\nc\nD\n",
+			wantHunks: 2,
+			want:      "a\nb\nc\nd\n",
+		},
+		{
+			name:      "accept all takes updated",
+			existing:  "a\nb\nc\nd\n",
+			updated:   "a\nB\nc\nD\n",
+			accept:    []int{0, 1},
+			wantHunks: 2,
+			want:      "a\nB\nc\nD\n",
+		},
+		{
+			name:      "accept single hunk",
+			existing:  "a\nb\nc\nd\n",
+			updated:   "a\nB\nc\nD\n",
+			accept:    []int{1},
+			wantHunks: 2,
+			want:      "a\nb\nc\nD\n",
+		},
+		{
+			name:      "insertion and deletion",
+			existing:  "a\nb\nc\n",
+			updated:   "x\na\nc\n",
+			accept:    []int{0},
+			wantHunks: 2,
+			want:      "x\na\nb\nc\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			segments := diff.Segments(diff.LineDiff(tt.existing, tt.updated))
+			hunks := diff.Hunks(segments)
+			if len(hunks) != tt.wantHunks {
+				t.Fatalf("got %d hunks, want %d", len(hunks), tt.wantHunks)
+			}
+
+			accepted := make(map[*diff.Hunk]bool)
+			for _, i := range tt.accept {
+				accepted[hunks[i]] = true
+			}
+
+			if got := diff.Merge(segments, accepted); got != tt.want {
+				t.Errorf("Merge() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
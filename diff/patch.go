@@ -0,0 +1,135 @@
+package diff
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/sergi/go-diff/diffmatchpatch"
+)
+
+// PatchContextLines is the number of unchanged lines around changes in a patch, like git uses by default
+const PatchContextLines = 3
+
+// patchLine is a line of a patch with its operation, the text includes the line break if there is one
+type patchLine struct {
+	op   operationRune
+	text string
+	// oldNum and newNum are the number of old and new lines before this line
+	oldNum int
+	newNum int
+}
+
+// WritePatch writes a unified diff of a file that can be applied with `git apply`.
+// The path is relative to the project root, an old content of nil creates a new file.
+func WritePatch(w io.Writer, path string, oldContent, newContent []byte) error {
+	path = filepath.ToSlash(path)
+
+	lines := patchLines(LineDiff(string(oldContent), string(newContent)))
+	hunks := patchHunks(lines, PatchContextLines)
+	if len(hunks) == 0 {
+		return nil
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "diff --git a/%s b/%s\n", path, path)
+	if oldContent == nil {
+		sb.WriteString("new file mode 100644\n")
+		sb.WriteString("--- /dev/null\n")
+	} else {
+		fmt.Fprintf(&sb, "--- a/%s\n", path)
+	}
+	fmt.Fprintf(&sb, "+++ b/%s\n", path)
+
+	for _, hunk := range hunks {
+		writeHunk(&sb, hunk)
+	}
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// patchLines splits a line diff into single lines and numbers them
+func patchLines(diffs []diffmatchpatch.Diff) []patchLine {
+	var lines []patchLine
+	oldNum, newNum := 0, 0
+	for _, d := range diffs {
+		for _, text := range strings.SplitAfter(d.Text, "\n") {
+			if text == "" {
+				continue
+			}
+
+			l := patchLine{text: text, oldNum: oldNum, newNum: newNum}
+			switch d.Type {
+			case diffmatchpatch.DiffEqual:
+				l.op = operationRuneEqual
+				oldNum++
+				newNum++
+			case diffmatchpatch.DiffDelete:
+				l.op = operationRuneDelete
+				oldNum++
+			case diffmatchpatch.DiffInsert:
+				l.op = operationRuneInsert
+				newNum++
+			}
+			lines = append(lines, l)
+		}
+	}
+	return lines
+}
+
+// patchHunks groups changed lines with their context, changes closer than twice the context share a hunk
+func patchHunks(lines []patchLine, context int) [][]patchLine {
+	var hunks [][]patchLine
+	start, end := -1, -1
+	for i, l := range lines {
+		if l.op == operationRuneEqual {
+			continue
+		}
+
+		if start >= 0 && i-context > end {
+			hunks = append(hunks, lines[start:end])
+			start = -1
+		}
+		if start < 0 {
+			start = max(0, i-context)
+		}
+		end = min(len(lines), i+context+1)
+	}
+	if start >= 0 {
+		hunks = append(hunks, lines[start:end])
+	}
+	return hunks
+}
+
+// writeHunk writes the header and lines of a hunk
+func writeHunk(sb *strings.Builder, hunk []patchLine) {
+	oldCount, newCount := 0, 0
+	for _, l := range hunk {
+		if l.op != operationRuneInsert {
+			oldCount++
+		}
+		if l.op != operationRuneDelete {
+			newCount++
+		}
+	}
+
+	// An empty range starts at the line before it
+	oldStart, newStart := hunk[0].oldNum, hunk[0].newNum
+	if oldCount > 0 {
+		oldStart++
+	}
+	if newCount > 0 {
+		newStart++
+	}
+
+	fmt.Fprintf(sb, "@@ -%d,%d +%d,%d @@\n", oldStart, oldCount, newStart, newCount)
+	for _, l := range hunk {
+		sb.WriteRune(rune(l.op))
+		sb.WriteString(l.text)
+		if !strings.HasSuffix(l.text, "\n") {
+			sb.WriteString("\n\\ No newline at end of file\n")
+		}
+	}
+}
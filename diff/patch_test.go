@@ -0,0 +1,84 @@
+package diff_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/networkteam/shry/diff"
+)
+
+func TestWritePatch(t *testing.T) {
+	tests := []struct {
+		name       string
+		oldContent []byte
+		newContent []byte
+		want       string
+	}{
+		{
+			name:       "new file",
+			newContent: []byte("a\nb\n"),
+			want: `diff --git a/dir/file.txt b/dir/file.txt
+new file mode 100644
+--- /dev/null
++++ b/dir/file.txt
+@@ -0,0 +1,2 @@
++a
++b
+`,
+		},
+		{
+			name:       "unchanged file",
+			oldContent: []byte("a\n"),
+			newContent: []byte("a\n"),
+			want:       "",
+		},
+		{
+			name:       "changes with context",
+			oldContent: []byte("1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n13\n14\n"),
+			newContent: []byte("1\n2\n3\n4\n5\nsix\n7\n8\n9\n10\n11\n12\n13\n14\n15\n"),
+			want: `diff --git a/dir/file.txt b/dir/file.txt
+--- a/dir/file.txt
++++ b/dir/file.txt
+@@ -3,7 +3,7 @@
+ 3
+ 4
+ 5
+-6
++six
+ 7
+ 8
+ 9
+@@ -12,3 +12,4 @@
+ 12
+ 13
+ 14
++15
+`,
+		},
+		{
+			name:       "missing newline at end of file",
+			oldContent: []byte("a\nb"),
+			newContent: []byte("a\nb\n"),
+			want: `diff --git a/dir/file.txt b/dir/file.txt
+--- a/dir/file.txt
++++ b/dir/file.txt
+@@ -1,2 +1,2 @@
+ a
+-b
+\ No newline at end of file
++b
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var sb strings.Builder
+			if err := diff.WritePatch(&sb, "dir/file.txt", tt.oldContent, tt.newContent); err != nil {
+				t.Fatalf("WritePatch() error = %v", err)
+			}
+			if sb.String() != tt.want {
+				t.Errorf("WritePatch() =\n%s\nwant\n%s", sb.String(), tt.want)
+			}
+		})
+	}
+}
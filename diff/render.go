@@ -0,0 +1,354 @@
+package diff
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/sergi/go-diff/diffmatchpatch"
+)
+
+// Layout is how old and new lines are arranged
+type Layout int
+
+const (
+	// LayoutUnified shows deleted lines above inserted lines
+	LayoutUnified Layout = iota
+	// LayoutSideBySide shows old lines on the left and new lines on the right
+	LayoutSideBySide
+)
+
+func (l Layout) String() string {
+	if l == LayoutSideBySide {
+		return "side-by-side"
+	}
+	return "unified"
+}
+
+// Granularity is how changes within a modified line are highlighted
+type Granularity int
+
+const (
+	// GranularityWord highlights changed words
+	GranularityWord Granularity = iota
+	// GranularityChar highlights changed characters
+	GranularityChar
+)
+
+func (g Granularity) String() string {
+	if g == GranularityChar {
+		return "char"
+	}
+	return "word"
+}
+
+// RenderOptions control how a diff is rendered
+type RenderOptions struct {
+	Layout      Layout
+	Granularity Granularity
+	// Context is the number of unchanged lines shown around changes
+	Context int
+	// Width is the available width, used to split the columns of the side-by-side layout
+	Width int
+}
+
+// DefaultRenderOptions returns the options for a unified diff with word highlighting
+func DefaultRenderOptions() RenderOptions {
+	return RenderOptions{
+		Layout:      LayoutUnified,
+		Granularity: GranularityWord,
+		Context:     contextLines,
+		Width:       80,
+	}
+}
+
+const (
+	// tabWidth is the number of spaces a tab is expanded to
+	tabWidth = 4
+	// sideBySideSeparator separates the columns of the side-by-side layout
+	sideBySideSeparator = " │ "
+)
+
+var (
+	lineNumberStyle    = lipgloss.NewStyle().Faint(true)
+	insertInlineStyle  = insertLineStyle.Bold(true).Underline(true)
+	deleteInlineStyle  = deleteLineStyle.Bold(true).Underline(true)
+	emptyColumnStyle   = lipgloss.NewStyle().Faint(true)
+	inlineTokenPattern = regexp.MustCompile(`[\p{L}\p{N}_]+|\s+|[^\p{L}\p{N}_\s]`)
+)
+
+// line is a line of the old or new text
+type line struct {
+	// oldNum and newNum are the 1-based line numbers, 0 if the line is not part of the text
+	oldNum int
+	newNum int
+	text   string
+	// inline is the diff to the paired line of a modified line, nil if the line has no pair
+	inline []diffmatchpatch.Diff
+}
+
+// block is either a run of unchanged lines or a hunk of deleted and inserted lines
+type block struct {
+	equal    []line
+	deleted  []line
+	inserted []line
+}
+
+// Render renders a line diff with line numbers and highlighted changes within modified lines
+func Render(diffs []diffmatchpatch.Diff, opts RenderOptions) string {
+	blocks, maxNum := buildBlocks(diffs, opts.Granularity)
+	r := renderer{
+		opts:     opts,
+		numWidth: len(fmt.Sprint(maxNum)),
+	}
+
+	var result []string
+	for i, b := range blocks {
+		if b.equal != nil {
+			result = append(result, r.renderEqual(b.equal, i == 0, i == len(blocks)-1)...)
+			continue
+		}
+		if opts.Layout == LayoutSideBySide {
+			result = append(result, r.renderSideBySideHunk(b)...)
+		} else {
+			result = append(result, r.renderUnifiedHunk(b)...)
+		}
+	}
+
+	return strings.Join(result, "\n")
+}
+
+// buildBlocks numbers the lines of a line diff and pairs deleted with inserted lines of each hunk
+func buildBlocks(diffs []diffmatchpatch.Diff, granularity Granularity) ([]block, int) {
+	var blocks []block
+	oldNum, newNum := 0, 0
+	for _, segment := range Segments(diffs) {
+		if segment.Hunk == nil {
+			var b block
+			for _, text := range splitLines(segment.Equal) {
+				oldNum++
+				newNum++
+				b.equal = append(b.equal, line{oldNum: oldNum, newNum: newNum, text: expandTabs(text)})
+			}
+			if b.equal != nil {
+				blocks = append(blocks, b)
+			}
+			continue
+		}
+
+		var b block
+		for _, text := range splitLines(segment.Hunk.Deleted) {
+			oldNum++
+			b.deleted = append(b.deleted, line{oldNum: oldNum, text: expandTabs(text)})
+		}
+		for _, text := range splitLines(segment.Hunk.Inserted) {
+			newNum++
+			b.inserted = append(b.inserted, line{newNum: newNum, text: expandTabs(text)})
+		}
+
+		// Lines at the same position of a hunk are treated as modified lines
+		for i := range min(len(b.deleted), len(b.inserted)) {
+			inline := inlineDiff(b.deleted[i].text, b.inserted[i].text, granularity)
+			b.deleted[i].inline = inline
+			b.inserted[i].inline = inline
+		}
+
+		blocks = append(blocks, b)
+	}
+
+	return blocks, max(oldNum, newNum)
+}
+
+// inlineDiff computes the changes between two lines by words or characters
+func inlineDiff(oldText, newText string, granularity Granularity) []diffmatchpatch.Diff {
+	dmp := diffmatchpatch.New()
+	if granularity == GranularityChar {
+		return dmp.DiffCleanupSemanticLossless(dmp.DiffMain(oldText, newText, false))
+	}
+
+	// Map every distinct word to a rune from the private use area and diff the runes
+	const firstRune = 0xE000
+	const maxTokens = 0xF8FF - firstRune
+	runes := make(map[string]rune)
+	var tokens []string
+	encode := func(text string) []rune {
+		var encoded []rune
+		for _, token := range inlineTokenPattern.FindAllString(text, -1) {
+			r, ok := runes[token]
+			if !ok {
+				r = rune(firstRune + len(tokens))
+				runes[token] = r
+				tokens = append(tokens, token)
+			}
+			encoded = append(encoded, r)
+		}
+		return encoded
+	}
+	oldRunes, newRunes := encode(oldText), encode(newText)
+	if len(tokens) > maxTokens {
+		return inlineDiff(oldText, newText, GranularityChar)
+	}
+
+	diffs := dmp.DiffMainRunes(oldRunes, newRunes, false)
+	for i, d := range diffs {
+		var sb strings.Builder
+		for _, r := range d.Text {
+			sb.WriteString(tokens[r-firstRune])
+		}
+		diffs[i].Text = sb.String()
+	}
+	return diffs
+}
+
+// renderer renders the blocks of a diff
+type renderer struct {
+	opts     RenderOptions
+	numWidth int
+}
+
+// renderEqual renders unchanged lines, omitting lines that are not within the context of a change
+func (r renderer) renderEqual(lines []line, isFirst, isLast bool) []string {
+	head, tail := r.opts.Context, r.opts.Context
+	if isFirst {
+		head = 0
+	}
+	if isLast {
+		tail = 0
+	}
+
+	render := func(l line) string {
+		if r.opts.Layout == LayoutSideBySide {
+			return r.joinColumns(
+				r.column(l.oldNum, l.text),
+				r.column(l.newNum, l.text),
+			)
+		}
+		return r.gutter(l.oldNum) + " " + r.gutter(l.newNum) + " " + formatInline(operationRuneEqual, l.text)
+	}
+
+	var result []string
+	if len(lines) <= head+tail+1 {
+		for _, l := range lines {
+			result = append(result, render(l))
+		}
+		return result
+	}
+
+	for _, l := range lines[:head] {
+		result = append(result, render(l))
+	}
+	result = append(result, omitLineStyle.Render(fmt.Sprintf("@@ <...> (%d more lines) @@", len(lines)-head-tail)))
+	for _, l := range lines[len(lines)-tail:] {
+		result = append(result, render(l))
+	}
+	return result
+}
+
+// renderUnifiedHunk renders deleted lines followed by inserted lines
+func (r renderer) renderUnifiedHunk(b block) []string {
+	var result []string
+	for _, l := range b.deleted {
+		result = append(result, r.gutter(l.oldNum)+" "+r.gutter(0)+" "+r.changedText(diffmatchpatch.DiffDelete, l))
+	}
+	for _, l := range b.inserted {
+		result = append(result, r.gutter(0)+" "+r.gutter(l.newNum)+" "+r.changedText(diffmatchpatch.DiffInsert, l))
+	}
+	return result
+}
+
+// renderSideBySideHunk renders deleted lines next to inserted lines
+func (r renderer) renderSideBySideHunk(b block) []string {
+	var result []string
+	for i := range max(len(b.deleted), len(b.inserted)) {
+		left := r.emptyColumn()
+		if i < len(b.deleted) {
+			left = r.changedColumn(diffmatchpatch.DiffDelete, b.deleted[i])
+		}
+		right := r.emptyColumn()
+		if i < len(b.inserted) {
+			right = r.changedColumn(diffmatchpatch.DiffInsert, b.inserted[i])
+		}
+		result = append(result, r.joinColumns(left, right))
+	}
+	return result
+}
+
+// changedText renders a deleted or inserted line, highlighting the changes within modified lines
+func (r renderer) changedText(op diffmatchpatch.Operation, l line) string {
+	opRune, lineStyle, inlineStyle := operationRuneInsert, insertLineStyle, insertInlineStyle
+	if op == diffmatchpatch.DiffDelete {
+		opRune, lineStyle, inlineStyle = operationRuneDelete, deleteLineStyle, deleteInlineStyle
+	}
+
+	if l.inline == nil {
+		return lineStyle.Render(formatInline(opRune, l.text))
+	}
+
+	var sb strings.Builder
+	sb.WriteString(lineStyle.Render(formatInline(opRune, "")))
+	for _, d := range l.inline {
+		switch d.Type {
+		case diffmatchpatch.DiffEqual:
+			sb.WriteString(lineStyle.Render(d.Text))
+		case op:
+			sb.WriteString(inlineStyle.Render(d.Text))
+		}
+	}
+	return sb.String()
+}
+
+// changedColumn renders a deleted or inserted line as a column of the side-by-side layout
+func (r renderer) changedColumn(op diffmatchpatch.Operation, l line) string {
+	num := l.newNum
+	if op == diffmatchpatch.DiffDelete {
+		num = l.oldNum
+	}
+	return r.fitColumn(r.gutter(num) + " " + r.changedText(op, l))
+}
+
+// column renders an unchanged line as a column of the side-by-side layout
+func (r renderer) column(num int, text string) string {
+	return r.fitColumn(r.gutter(num) + " " + formatInline(operationRuneEqual, text))
+}
+
+// emptyColumn renders the missing side of an unpaired line
+func (r renderer) emptyColumn() string {
+	return r.fitColumn(emptyColumnStyle.Render(strings.Repeat("·", r.numWidth)))
+}
+
+// fitColumn truncates or pads the rendered column to the column width
+func (r renderer) fitColumn(s string) string {
+	width := r.columnWidth()
+	s = ansi.Truncate(s, width, "…")
+	return s + strings.Repeat(" ", max(0, width-ansi.StringWidth(s)))
+}
+
+// joinColumns joins the left and right column of the side-by-side layout
+func (r renderer) joinColumns(left, right string) string {
+	return left + lineNumberStyle.Render(sideBySideSeparator) + right
+}
+
+// columnWidth is the width of each column of the side-by-side layout
+func (r renderer) columnWidth() int {
+	return max(20, (r.opts.Width-ansi.StringWidth(sideBySideSeparator))/2)
+}
+
+// gutter renders a line number, or blank space if the line is not part of the text
+func (r renderer) gutter(num int) string {
+	if num == 0 {
+		return strings.Repeat(" ", r.numWidth)
+	}
+	return lineNumberStyle.Render(fmt.Sprintf("%*d", r.numWidth, num))
+}
+
+// formatInline formats a line with its operation for the compact layouts with line numbers
+func formatInline(opRune operationRune, text string) string {
+	return string(opRune) + " " + text
+}
+
+// expandTabs replaces tabs with spaces so the width of a line is known
+func expandTabs(text string) string {
+	return strings.ReplaceAll(text, "\t", strings.Repeat(" ", tabWidth))
+}
@@ -0,0 +1,65 @@
+package diff_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/networkteam/shry/diff"
+)
+
+func TestRender(t *testing.T) {
+	existing := "a\nb\nc\nd\ne\nf\ng\nname: foo bar\n"
+	updated := "a\nb\nc\nd\ne\nf\ng\nname: foo baz\nh\n"
+
+	tests := []struct {
+		name string
+		opts diff.RenderOptions
+		want []string
+	}{
+		{
+			name: "unified with line numbers",
+			opts: diff.RenderOptions{Layout: diff.LayoutUnified, Context: 2, Width: 80},
+			want: []string{
+				"@@ <...> (5 more lines) @@",
+				"6 6   f",
+				"7 7   g",
+				"8   - name: foo bar",
+				"  8 + name: foo baz",
+				"  9 + h",
+			},
+		},
+		{
+			name: "unified without context",
+			opts: diff.RenderOptions{Layout: diff.LayoutUnified, Context: 0, Width: 80},
+			want: []string{
+				"@@ <...> (7 more lines) @@",
+				"8   - name: foo bar",
+				"  8 + name: foo baz",
+				"  9 + h",
+			},
+		},
+		{
+			name: "side-by-side",
+			opts: diff.RenderOptions{Layout: diff.LayoutSideBySide, Context: 1, Width: 43},
+			want: []string{
+				"@@ <...> (6 more lines) @@",
+				"7   g                │ 7   g",
+				"8 - name: foo bar    │ 8 + name: foo baz",
+				"·                    │ 9 + h",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rendered := diff.Render(diff.LineDiff(existing, updated), tt.opts)
+
+			var got []string
+			for _, line := range strings.Split(rendered, "\n") {
+				got = append(got, strings.TrimRight(line, " "))
+			}
+			if strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+				t.Errorf("Render() =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(tt.want, "\n"))
+			}
+		})
+	}
+}
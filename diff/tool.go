@@ -0,0 +1,106 @@
+package diff
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// toolFiles are the temporary files passed to an external tool
+type toolFiles struct {
+	dir    string
+	local  string
+	remote string
+	merged string
+}
+
+// newToolFiles writes the existing (local) and updated (remote) content to temporary files named after the destination.
+// The merged file starts with the existing content.
+func newToolFiles(dst string, existing, updated []byte) (*toolFiles, error) {
+	dir, err := os.MkdirTemp("", "shry-tool-")
+	if err != nil {
+		return nil, fmt.Errorf("creating temporary directory: %w", err)
+	}
+
+	// Keep the extension so tools can detect the file type
+	base := filepath.Base(dst)
+	ext := filepath.Ext(base)
+	name := strings.TrimSuffix(base, ext)
+
+	files := &toolFiles{
+		dir:    dir,
+		local:  filepath.Join(dir, name+".LOCAL"+ext),
+		remote: filepath.Join(dir, name+".REMOTE"+ext),
+		merged: filepath.Join(dir, base),
+	}
+	for path, content := range map[string][]byte{
+		files.local:  existing,
+		files.remote: updated,
+		files.merged: existing,
+	} {
+		if err := os.WriteFile(path, content, 0600); err != nil {
+			files.remove()
+			return nil, fmt.Errorf("writing temporary file: %w", err)
+		}
+	}
+
+	return files, nil
+}
+
+// run runs the shell command with the git-style $LOCAL, $REMOTE, $BASE and $MERGED variables.
+// There is no common ancestor, so $BASE is the existing file.
+func (f *toolFiles) run(command string) error {
+	cmd := exec.Command("sh", "-c", command)
+	cmd.Env = append(os.Environ(),
+		"LOCAL="+f.local,
+		"REMOTE="+f.remote,
+		"BASE="+f.local,
+		"MERGED="+f.merged,
+	)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("running %q: %w", command, err)
+	}
+	return nil
+}
+
+// remove deletes the temporary files
+func (f *toolFiles) remove() {
+	_ = os.RemoveAll(f.dir)
+}
+
+// RunDiffTool compares the existing content with the updated content of the destination in an external diff tool
+func RunDiffTool(command, dst string, existing, updated []byte) error {
+	files, err := newToolFiles(dst, existing, updated)
+	if err != nil {
+		return err
+	}
+	defer files.remove()
+
+	return files.run(command)
+}
+
+// RunMergeTool merges the updated content into the existing content of the destination with an external merge tool.
+// It returns the content the tool wrote to $MERGED.
+func RunMergeTool(command, dst string, existing, updated []byte) ([]byte, error) {
+	files, err := newToolFiles(dst, existing, updated)
+	if err != nil {
+		return nil, err
+	}
+	defer files.remove()
+
+	if err := files.run(command); err != nil {
+		return nil, err
+	}
+
+	merged, err := os.ReadFile(files.merged)
+	if err != nil {
+		return nil, fmt.Errorf("reading merge result: %w", err)
+	}
+	return merged, nil
+}
@@ -0,0 +1,48 @@
+package diff_test
+
+import (
+	"testing"
+
+	"github.com/networkteam/shry/diff"
+)
+
+func TestRunMergeTool(t *testing.T) {
+	tests := []struct {
+		name    string
+		command string
+		want    string
+		wantErr bool
+	}{
+		{
+			name:    "take remote",
+			command: `cp "$REMOTE" "$MERGED"`,
+			want:    "updated\n",
+		},
+		{
+			name:    "keep merged",
+			command: `test -f "$LOCAL" && test -f "$BASE"`,
+			want:    "existing\n",
+		},
+		{
+			name:    "keeps extension",
+			command: `case "$LOCAL" in *.LOCAL.yaml) echo merged > "$MERGED";; *) exit 1;; esac`,
+			want:    "merged\n",
+		},
+		{
+			name:    "tool fails",
+			command: `exit 1`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := diff.RunMergeTool(tt.command, "dir/file.yaml", []byte("existing\n"), []byte("updated\n"))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("RunMergeTool() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if string(got) != tt.want {
+				t.Errorf("RunMergeTool() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -1,22 +1,30 @@
 package diff
 
 import (
+	"fmt"
+
 	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/networkteam/shry/ui"
+	"github.com/sergi/go-diff/diffmatchpatch"
 )
 
+// maxContextLines limits the context that can be set in the viewer
+const maxContextLines = 50
+
 type viewerModel struct {
 	viewport viewport.Model
 	ready    bool
-	content  string
+	diffs    []diffmatchpatch.Diff
+	opts     RenderOptions
 }
 
-func newViewerModel(content string) viewerModel {
+func newViewerModel(diffs []diffmatchpatch.Diff) viewerModel {
 	return viewerModel{
 		viewport: viewport.New(0, 0),
-		content:  content,
+		diffs:    diffs,
+		opts:     DefaultRenderOptions(),
 	}
 }
 
@@ -32,6 +40,22 @@ func (m viewerModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		switch msg.String() {
 		case "q", "ctrl+c", "esc":
 			return m, tea.Quit
+		case "s":
+			m.opts.Layout = (m.opts.Layout + 1) % 2
+			m.refresh()
+			return m, nil
+		case "w":
+			m.opts.Granularity = (m.opts.Granularity + 1) % 2
+			m.refresh()
+			return m, nil
+		case "+", "=":
+			m.opts.Context = min(m.opts.Context+1, maxContextLines)
+			m.refresh()
+			return m, nil
+		case "-":
+			m.opts.Context = max(m.opts.Context-1, 0)
+			m.refresh()
+			return m, nil
 		}
 
 	case tea.WindowSizeMsg:
@@ -42,18 +66,24 @@ func (m viewerModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		if !m.ready {
 			m.viewport = viewport.New(msg.Width, msg.Height-verticalMarginHeight)
 			m.viewport.YPosition = headerHeight
-			m.viewport.SetContent(m.content)
 			m.ready = true
 		} else {
 			m.viewport.Width = msg.Width
 			m.viewport.Height = msg.Height - verticalMarginHeight
 		}
+		m.opts.Width = msg.Width
+		m.refresh()
 	}
 
 	m.viewport, cmd = m.viewport.Update(msg)
 	return m, cmd
 }
 
+// refresh renders the diff with the current options
+func (m *viewerModel) refresh() {
+	m.viewport.SetContent(Render(m.diffs, m.opts))
+}
+
 func (m viewerModel) View() string {
 	if !m.ready {
 		return "\n  Initializing..."
@@ -64,8 +94,8 @@ func (m viewerModel) View() string {
 		Background(ui.PrimaryColor).
 		Padding(0, 1)
 
-	header := headerStyle.Render("Diff Viewer")
-	footer := ui.HelpStyle.Render("↑/↓: scroll • q/esc: quit • space/b: page up/down")
+	header := headerStyle.Render("Diff Viewer") + ui.HelpStyle.Render(fmt.Sprintf("  %s • %s changes • context %d", m.opts.Layout, m.opts.Granularity, m.opts.Context))
+	footer := ui.HelpStyle.Render("↑/↓: scroll • space/b: page up/down • s: side-by-side/unified • w: word/char changes • +/-: context • q/esc: quit")
 
 	return lipgloss.JoinVertical(lipgloss.Left,
 		header,
@@ -76,8 +106,9 @@ func (m viewerModel) View() string {
 	)
 }
 
-func ShowDiff(content string) error {
-	m := newViewerModel(content)
+// ShowDiff displays a line diff in an interactive viewer
+func ShowDiff(diffs []diffmatchpatch.Diff) error {
+	m := newViewerModel(diffs)
 
 	p := tea.NewProgram(m,
 		tea.WithAltScreen(),
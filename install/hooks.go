@@ -0,0 +1,61 @@
+package install
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+
+	"github.com/networkteam/shry/config"
+	"github.com/networkteam/shry/template"
+)
+
+// Hook is a resolved command of a component
+type Hook struct {
+	// Component that declares the hook
+	Component *config.Component
+	// Command with resolved variables
+	Command string
+}
+
+// PostAddHooks returns the postAdd hooks of the components in order
+func PostAddHooks(components []*config.Component, variables map[string]any) ([]Hook, error) {
+	return resolveHooks(components, variables, func(hooks config.Hooks) []string { return hooks.PostAdd })
+}
+
+// PreRemoveHooks returns the preRemove hooks of the components in order
+func PreRemoveHooks(components []*config.Component, variables map[string]any) ([]Hook, error) {
+	return resolveHooks(components, variables, func(hooks config.Hooks) []string { return hooks.PreRemove })
+}
+
+// resolveHooks resolves the variables in the selected commands of all components, default variables of components apply
+func resolveHooks(components []*config.Component, projectVariables map[string]any, commands func(config.Hooks) []string) ([]Hook, error) {
+	var hooks []Hook
+	for _, component := range components {
+		variables := component.ResolveVariables(projectVariables)
+		for _, command := range commands(component.Hooks) {
+			resolved, err := template.Resolve(command, variables)
+			if err != nil {
+				return nil, fmt.Errorf("resolving hook of component %s: %w", component.Name, err)
+			}
+			hooks = append(hooks, Hook{Component: component, Command: resolved})
+		}
+	}
+	return hooks, nil
+}
+
+// RunHooks runs the hooks one after another with the shell in the project directory.
+// It stops at the first failing hook.
+func RunHooks(projectDir string, hooks []Hook) error {
+	for _, hook := range hooks {
+		cmd := exec.Command("sh", "-c", hook.Command)
+		cmd.Dir = projectDir
+		cmd.Stdin = os.Stdin
+		cmd.Stdout = os.Stdout
+		cmd.Stderr = os.Stderr
+
+		if err := cmd.Run(); err != nil {
+			return fmt.Errorf("running hook %q of component %s: %w", hook.Command, hook.Component.Name, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package install_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/networkteam/shry/config"
+	"github.com/networkteam/shry/install"
+)
+
+func TestHooks(t *testing.T) {
+	components := []*config.Component{
+		{Name: "button", Hooks: config.Hooks{PostAdd: []string{"echo {{package}} >> hooks.log"}}},
+		{Name: "card", Hooks: config.Hooks{
+			PostAdd:   []string{"echo card >> hooks.log", "test -f hooks.log"},
+			PreRemove: []string{"echo removed >> hooks.log"},
+		}},
+	}
+
+	tests := []struct {
+		name      string
+		variables map[string]any
+		preRemove bool
+		wantErr   bool
+		wantLog   string
+	}{
+		{
+			name:      "post add hooks in order",
+			variables: map[string]any{"package": "Acme.Site"},
+			wantLog:   "Acme.Site\ncard\n",
+		},
+		{
+			name:      "pre remove hooks",
+			variables: map[string]any{},
+			preRemove: true,
+			wantLog:   "removed\n",
+		},
+		{
+			name:      "missing variable",
+			variables: map[string]any{},
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resolve := install.PostAddHooks
+			if tt.preRemove {
+				resolve = install.PreRemoveHooks
+			}
+			hooks, err := resolve(components, tt.variables)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("resolving hooks error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			projectDir := t.TempDir()
+			if err := install.RunHooks(projectDir, hooks); err != nil {
+				t.Fatalf("RunHooks() unexpected error: %v", err)
+			}
+
+			log, err := os.ReadFile(filepath.Join(projectDir, "hooks.log"))
+			if err != nil {
+				t.Fatalf("reading hooks log: %v", err)
+			}
+			if string(log) != tt.wantLog {
+				t.Errorf("hooks log = %q, want %q", log, tt.wantLog)
+			}
+		})
+	}
+}
+
+func TestRunHooksStopsAtFailure(t *testing.T) {
+	component := &config.Component{Name: "card"}
+	hooks := []install.Hook{
+		{Component: component, Command: "exit 3"},
+		{Component: component, Command: "touch ran"},
+	}
+
+	projectDir := t.TempDir()
+	if err := install.RunHooks(projectDir, hooks); err == nil {
+		t.Fatal("RunHooks() expected error, got nil")
+	}
+	if _, err := os.Stat(filepath.Join(projectDir, "ran")); err == nil {
+		t.Error("RunHooks() ran a hook after a failing hook")
+	}
+}
@@ -0,0 +1,135 @@
+package install
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+// journal records files before they are replaced, so a failed apply can be rolled back.
+// Backups of existing files are stored in a temporary directory until the journal is committed.
+type journal struct {
+	dir     string
+	entries []journalEntry
+	// createdDirs are directories created for new files, in order of creation
+	createdDirs []string
+}
+
+// journalEntry is a destination file written by an apply
+type journalEntry struct {
+	path string
+	// backup of the previous file, empty if the file did not exist
+	backup string
+	mode   os.FileMode
+}
+
+// newJournal creates a journal with an empty backup directory
+func newJournal() (*journal, error) {
+	dir, err := os.MkdirTemp("", "shry-journal-")
+	if err != nil {
+		return nil, fmt.Errorf("creating journal: %w", err)
+	}
+	return &journal{dir: dir}, nil
+}
+
+// mkdirAll creates the directory and its parents, recording every directory that did not exist
+func (j *journal) mkdirAll(dir string) error {
+	var missing []string
+	for d := dir; ; d = filepath.Dir(d) {
+		if _, err := os.Stat(d); err == nil || filepath.Dir(d) == d {
+			break
+		}
+		missing = append(missing, d)
+	}
+
+	for i := len(missing) - 1; i >= 0; i-- {
+		if err := os.Mkdir(missing[i], 0755); err != nil && !errors.Is(err, os.ErrExist) {
+			return fmt.Errorf("creating destination directory: %w", err)
+		}
+		j.createdDirs = append(j.createdDirs, missing[i])
+	}
+
+	return nil
+}
+
+// record backs up the file at path (if it exists) before it is replaced
+func (j *journal) record(path string) error {
+	entry := journalEntry{path: path}
+
+	info, err := os.Stat(path)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+	case err != nil:
+		return fmt.Errorf("backing up %s: %w", path, err)
+	default:
+		entry.mode = info.Mode().Perm()
+		entry.backup = filepath.Join(j.dir, strconv.Itoa(len(j.entries)))
+		if err := copyFile(path, entry.backup); err != nil {
+			return fmt.Errorf("backing up %s: %w", path, err)
+		}
+	}
+
+	j.entries = append(j.entries, entry)
+	return nil
+}
+
+// rollback restores all recorded files in reverse order and removes created directories.
+// The backups are kept if a file cannot be restored.
+func (j *journal) rollback() error {
+	var errs []error
+	for i := len(j.entries) - 1; i >= 0; i-- {
+		entry := j.entries[i]
+		if entry.backup == "" {
+			if err := os.Remove(entry.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+				errs = append(errs, fmt.Errorf("removing %s: %w", entry.path, err))
+			}
+			continue
+		}
+
+		if err := copyFile(entry.backup, entry.path); err != nil {
+			errs = append(errs, fmt.Errorf("restoring %s: %w", entry.path, err))
+			continue
+		}
+		if err := os.Chmod(entry.path, entry.mode); err != nil {
+			errs = append(errs, fmt.Errorf("restoring mode of %s: %w", entry.path, err))
+		}
+	}
+
+	// Directories are only removed if they are empty again
+	for i := len(j.createdDirs) - 1; i >= 0; i-- {
+		_ = os.Remove(j.createdDirs[i])
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("rolling back, backups are kept in %s: %w", j.dir, errors.Join(errs...))
+	}
+
+	return os.RemoveAll(j.dir)
+}
+
+// commit discards the backups
+func (j *journal) commit() error {
+	return os.RemoveAll(j.dir)
+}
+
+// copyFile copies the content of src to dst
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
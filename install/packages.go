@@ -0,0 +1,332 @@
+package install
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/networkteam/shry/config"
+	"github.com/networkteam/shry/merge"
+)
+
+// Ecosystem is a package manager with its project manifest
+type Ecosystem string
+
+const (
+	// EcosystemNPM are packages in package.json
+	EcosystemNPM Ecosystem = "npm"
+	// EcosystemComposer are packages in composer.json
+	EcosystemComposer Ecosystem = "composer"
+	// EcosystemGo are modules in go.mod
+	EcosystemGo Ecosystem = "go"
+)
+
+// Manifest returns the file name of the project manifest of the ecosystem
+func (e Ecosystem) Manifest() string {
+	switch e {
+	case EcosystemNPM:
+		return "package.json"
+	case EcosystemComposer:
+		return "composer.json"
+	default:
+		return "go.mod"
+	}
+}
+
+// Package is a package required by a component
+type Package struct {
+	Ecosystem Ecosystem
+	Name      string
+	// Version constraint, empty for any version
+	Version string
+	// Component requiring the package
+	Component *config.Component
+}
+
+func (p Package) String() string {
+	if p.Version == "" {
+		return p.Name
+	}
+	return p.Name + " " + p.Version
+}
+
+// RequiredPackages returns the packages of all components sorted by ecosystem and name.
+// A package required by several components is returned for the first component.
+func RequiredPackages(components []*config.Component) []Package {
+	var packages []Package
+	add := func(component *config.Component, ecosystem Ecosystem, requirements map[string]string) {
+		for name, version := range requirements {
+			exists := slices.ContainsFunc(packages, func(p Package) bool {
+				return p.Ecosystem == ecosystem && p.Name == name
+			})
+			if !exists {
+				packages = append(packages, Package{Ecosystem: ecosystem, Name: name, Version: version, Component: component})
+			}
+		}
+	}
+	for _, component := range components {
+		add(component, EcosystemNPM, component.Packages.NPM)
+		add(component, EcosystemComposer, component.Packages.Composer)
+		add(component, EcosystemGo, component.Packages.Go)
+	}
+
+	slices.SortStableFunc(packages, func(a, b Package) int {
+		if a.Ecosystem != b.Ecosystem {
+			return strings.Compare(string(a.Ecosystem), string(b.Ecosystem))
+		}
+		return strings.Compare(a.Name, b.Name)
+	})
+	return packages
+}
+
+// MissingPackages returns the packages that are not declared in the manifests of the project.
+// All packages of an ecosystem are missing if the project has no manifest for it.
+func MissingPackages(projectDir string, packages []Package) ([]Package, error) {
+	declaredByEcosystem := make(map[Ecosystem]map[string]bool)
+
+	var missing []Package
+	for _, pkg := range packages {
+		declared, loaded := declaredByEcosystem[pkg.Ecosystem]
+		if !loaded {
+			var err error
+			declared, err = declaredPackages(projectDir, pkg.Ecosystem)
+			if err != nil {
+				return nil, err
+			}
+			declaredByEcosystem[pkg.Ecosystem] = declared
+		}
+
+		if !declared[pkg.Name] {
+			missing = append(missing, pkg)
+		}
+	}
+	return missing, nil
+}
+
+// declaredPackages reads the names of all packages in the manifest of the ecosystem
+func declaredPackages(projectDir string, ecosystem Ecosystem) (map[string]bool, error) {
+	content, err := os.ReadFile(filepath.Join(projectDir, ecosystem.Manifest()))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", ecosystem.Manifest(), err)
+	}
+
+	declared := make(map[string]bool)
+	if ecosystem == EcosystemGo {
+		for _, name := range goModRequires(content) {
+			declared[name] = true
+		}
+		return declared, nil
+	}
+
+	var manifest map[string]json.RawMessage
+	if err := json.Unmarshal(content, &manifest); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", ecosystem.Manifest(), err)
+	}
+	sections := []string{"dependencies", "devDependencies", "peerDependencies", "optionalDependencies"}
+	if ecosystem == EcosystemComposer {
+		sections = []string{"require", "require-dev"}
+	}
+	for _, section := range sections {
+		raw, exists := manifest[section]
+		if !exists {
+			continue
+		}
+		var requirements map[string]any
+		if err := json.Unmarshal(raw, &requirements); err != nil {
+			return nil, fmt.Errorf("parsing %s in %s: %w", section, ecosystem.Manifest(), err)
+		}
+		for name := range requirements {
+			declared[name] = true
+		}
+	}
+	return declared, nil
+}
+
+// goModRequires returns the module paths of all require directives in a go.mod file
+func goModRequires(content []byte) []string {
+	var modules []string
+	inBlock := false
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		line, _, _ := strings.Cut(scanner.Text(), "//")
+		fields := strings.Fields(line)
+		switch {
+		case len(fields) == 0:
+		case inBlock && fields[0] == ")":
+			inBlock = false
+		case inBlock:
+			modules = append(modules, fields[0])
+		case fields[0] == "require" && len(fields) == 2 && fields[1] == "(":
+			inBlock = true
+		case fields[0] == "require" && len(fields) >= 2:
+			modules = append(modules, fields[1])
+		}
+	}
+	return modules
+}
+
+// ManifestUpdate is a manifest of the project with added packages
+type ManifestUpdate struct {
+	// Path of the manifest relative to the project directory
+	Path string
+	// Existing content of the manifest, nil if it does not exist
+	Existing []byte
+	// Content with the added packages
+	Content []byte
+}
+
+// ManifestUpdates adds the packages to the package.json and composer.json manifests, keeping their formatting.
+// Go modules are returned as not updated, they need checksums and must be added with `go get`.
+func ManifestUpdates(projectDir string, packages []Package) ([]*ManifestUpdate, []Package, error) {
+	return manifestUpdates(packages, func(path string) ([]byte, error) {
+		return readManifest(projectDir, path)
+	})
+}
+
+// readManifest reads a manifest of the project, it is nil if it does not exist
+func readManifest(projectDir string, path string) ([]byte, error) {
+	existing, err := os.ReadFile(filepath.Join(projectDir, path))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("reading %s: %w", path, err)
+	}
+	return existing, nil
+}
+
+// manifestUpdates adds the packages to the manifests with the existing content returned by read
+func manifestUpdates(packages []Package, read func(path string) ([]byte, error)) ([]*ManifestUpdate, []Package, error) {
+	var updates []*ManifestUpdate
+	var notUpdated []Package
+
+	for _, ecosystem := range []Ecosystem{EcosystemNPM, EcosystemComposer} {
+		section := "dependencies"
+		if ecosystem == EcosystemComposer {
+			section = "require"
+		}
+
+		requirements := make(map[string]string)
+		for _, pkg := range packages {
+			if pkg.Ecosystem != ecosystem {
+				continue
+			}
+			version := pkg.Version
+			if version == "" {
+				version = "*"
+			}
+			requirements[pkg.Name] = version
+		}
+		if len(requirements) == 0 {
+			continue
+		}
+
+		path := ecosystem.Manifest()
+		existing, err := read(path)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		// Packages are added in the order of their names, a missing manifest is created
+		upstream, err := json.MarshalIndent(map[string]any{section: requirements}, "", "  ")
+		if err != nil {
+			return nil, nil, fmt.Errorf("encoding packages: %w", err)
+		}
+		content := append(upstream, '\n')
+		if len(bytes.TrimSpace(existing)) > 0 {
+			content, _, err = merge.Merge(merge.StrategyJSON, path, existing, upstream)
+			if err != nil {
+				return nil, nil, fmt.Errorf("adding packages to %s: %w", path, err)
+			}
+		}
+
+		updates = append(updates, &ManifestUpdate{Path: path, Existing: existing, Content: content})
+	}
+
+	for _, pkg := range packages {
+		if pkg.Ecosystem == EcosystemGo {
+			notUpdated = append(notUpdated, pkg)
+		}
+	}
+
+	return updates, notUpdated, nil
+}
+
+// Apply writes the updated manifest
+func (u *ManifestUpdate) Apply(projectDir string) error {
+	if err := os.WriteFile(filepath.Join(projectDir, u.Path), u.Content, 0644); err != nil {
+		return fmt.Errorf("writing %s: %w", u.Path, err)
+	}
+	return nil
+}
+
+// InstallCommands returns the shell commands to install the packages, one per ecosystem.
+// The npm client is detected by the lock file in the project directory.
+func InstallCommands(projectDir string, packages []Package) []string {
+	var commands []string
+	for _, ecosystem := range []Ecosystem{EcosystemNPM, EcosystemComposer, EcosystemGo} {
+		var args []string
+		for _, pkg := range packages {
+			if pkg.Ecosystem != ecosystem {
+				continue
+			}
+
+			arg := pkg.Name
+			switch {
+			case pkg.Version == "":
+			case ecosystem == EcosystemComposer:
+				arg += ":" + pkg.Version
+			default:
+				arg += "@" + pkg.Version
+			}
+			args = append(args, shellQuote(arg))
+		}
+		if len(args) == 0 {
+			continue
+		}
+
+		var command string
+		switch ecosystem {
+		case EcosystemNPM:
+			command = npmInstallCommand(projectDir)
+		case EcosystemComposer:
+			command = "composer require"
+		case EcosystemGo:
+			command = "go get"
+		}
+		commands = append(commands, command+" "+strings.Join(args, " "))
+	}
+	return commands
+}
+
+// npmInstallCommand returns the command of the npm client used by the project
+func npmInstallCommand(projectDir string) string {
+	for _, client := range []struct{ lockFile, command string }{
+		{"pnpm-lock.yaml", "pnpm add"},
+		{"yarn.lock", "yarn add"},
+		{"bun.lock", "bun add"},
+		{"bun.lockb", "bun add"},
+	} {
+		if _, err := os.Stat(filepath.Join(projectDir, client.lockFile)); err == nil {
+			return client.command
+		}
+	}
+	return "npm install"
+}
+
+// shellQuote quotes an argument for the shell if it contains other than safe characters
+func shellQuote(arg string) string {
+	safe := func(r rune) bool {
+		return r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || strings.ContainsRune("@/._:-+=", r)
+	}
+	if strings.IndexFunc(arg, func(r rune) bool { return !safe(r) }) < 0 {
+		return arg
+	}
+	return "'" + strings.ReplaceAll(arg, "'", `'\''`) + "'"
+}
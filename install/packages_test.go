@@ -0,0 +1,150 @@
+package install_test
+
+import (
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/networkteam/shry/config"
+	"github.com/networkteam/shry/install"
+)
+
+func TestPackages(t *testing.T) {
+	tests := []struct {
+		name          string
+		files         map[string]string
+		packages      config.Packages
+		wantMissing   []string
+		wantManifests map[string]string
+		wantCommands  []string
+	}{
+		{
+			name: "declared packages are not missing",
+			files: map[string]string{
+				"package.json":  "{\n  \"dependencies\": {\"react\": \"^18\"},\n  \"devDependencies\": {\"clsx\": \"^2\"}\n}\n",
+				"composer.json": "{\"require\": {\"neos/neos\": \"^8.3\"}}\n",
+				"go.mod":        "module example.com/site\n\nrequire github.com/a/b v1.0.0\n\nrequire (\n\tgithub.com/c/d v1.2.0 // indirect\n)\n",
+			},
+			packages: config.Packages{
+				NPM:      map[string]string{"react": "^18", "clsx": "^2"},
+				Composer: map[string]string{"neos/neos": "^8.3"},
+				Go:       map[string]string{"github.com/a/b": "v1.0.0", "github.com/c/d": ""},
+			},
+		},
+		{
+			name: "missing packages are added to manifests",
+			files: map[string]string{
+				"package.json": "{\n\t\"name\": \"site\",\n\t\"dependencies\": {\n\t\t\"react\": \"^18\"\n\t}\n}\n",
+				"yarn.lock":    "",
+			},
+			packages: config.Packages{
+				NPM:      map[string]string{"react": "^18", "clsx": "^2", "@headlessui/react": ""},
+				Composer: map[string]string{"neos/fusion-form": "^2.0"},
+				Go:       map[string]string{"github.com/a/b": "v1.0.0"},
+			},
+			wantMissing: []string{
+				"composer neos/fusion-form ^2.0",
+				"go github.com/a/b v1.0.0",
+				"npm @headlessui/react",
+				"npm clsx ^2",
+			},
+			wantManifests: map[string]string{
+				"package.json":  "{\n\t\"name\": \"site\",\n\t\"dependencies\": {\n\t\t\"react\": \"^18\",\n\t\t\"@headlessui/react\": \"*\",\n\t\t\"clsx\": \"^2\"\n\t}\n}\n",
+				"composer.json": "{\n  \"require\": {\n    \"neos/fusion-form\": \"^2.0\"\n  }\n}\n",
+			},
+			wantCommands: []string{
+				"yarn add @headlessui/react 'clsx@^2'",
+				"composer require 'neos/fusion-form:^2.0'",
+				"go get github.com/a/b@v1.0.0",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			projectDir := t.TempDir()
+			for name, content := range tt.files {
+				writeFile(t, filepath.Join(projectDir, name), content)
+			}
+
+			component := &config.Component{Name: "button", Packages: tt.packages}
+			missing, err := install.MissingPackages(projectDir, install.RequiredPackages([]*config.Component{component}))
+			if err != nil {
+				t.Fatalf("MissingPackages() unexpected error: %v", err)
+			}
+
+			var gotMissing []string
+			for _, pkg := range missing {
+				gotMissing = append(gotMissing, string(pkg.Ecosystem)+" "+pkg.String())
+			}
+			if !slices.Equal(gotMissing, tt.wantMissing) {
+				t.Errorf("MissingPackages() = %q, want %q", gotMissing, tt.wantMissing)
+			}
+
+			updates, _, err := install.ManifestUpdates(projectDir, missing)
+			if err != nil {
+				t.Fatalf("ManifestUpdates() unexpected error: %v", err)
+			}
+			if len(updates) != len(tt.wantManifests) {
+				t.Errorf("ManifestUpdates() returned %d updates, want %d", len(updates), len(tt.wantManifests))
+			}
+			for _, update := range updates {
+				if want := tt.wantManifests[update.Path]; string(update.Content) != want {
+					t.Errorf("ManifestUpdates() %s = %q, want %q", update.Path, update.Content, want)
+				}
+			}
+
+			if got := install.InstallCommands(projectDir, missing); !slices.Equal(got, tt.wantCommands) {
+				t.Errorf("InstallCommands() = %q, want %q", got, tt.wantCommands)
+			}
+		})
+	}
+}
+
+func TestPatchWithManifestUpdates(t *testing.T) {
+	reg := newTestRegistry(t, map[string]string{
+		"react/button/shry.yaml":    "name: button\nplatform: react\nfiles:\n  - src: package.json\n    dst: package.json\n    merge: json\npackages:\n  npm:\n    clsx: ^2\n",
+		"react/button/package.json": "{\"scripts\": {\"lint\": \"eslint .\"}}\n",
+	})
+
+	projectDir := t.TempDir()
+	writeFile(t, filepath.Join(projectDir, "package.json"), "{\n  \"name\": \"site\"\n}\n")
+	projectConfig := &config.ProjectConfig{ProjectDir: projectDir, Platform: "react"}
+
+	components, err := reg.ResolveComponents("react", []string{"button"})
+	if err != nil {
+		t.Fatalf("ResolveComponents() unexpected error: %v", err)
+	}
+	plan, err := install.NewPlan(reg, projectConfig, components)
+	if err != nil {
+		t.Fatalf("NewPlan() unexpected error: %v", err)
+	}
+	for _, change := range plan.Conflicts() {
+		change.Content = change.Merged
+		change.Action = install.ActionMerge
+	}
+
+	missing, err := install.MissingPackages(projectDir, install.RequiredPackages(components))
+	if err != nil {
+		t.Fatalf("MissingPackages() unexpected error: %v", err)
+	}
+	updates, _, err := plan.ManifestUpdates(missing)
+	if err != nil {
+		t.Fatalf("ManifestUpdates() unexpected error: %v", err)
+	}
+
+	var patch strings.Builder
+	if err := plan.Patch(&patch, updates...); err != nil {
+		t.Fatalf("Patch() unexpected error: %v", err)
+	}
+
+	if n := strings.Count(patch.String(), "+++ b/package.json"); n != 1 {
+		t.Errorf("Patch() contains %d diffs of package.json, want 1:\n%s", n, patch.String())
+	}
+	for _, want := range []string{"+  \"scripts\": {", "+    \"clsx\": \"^2\""} {
+		if !strings.Contains(patch.String(), want) {
+			t.Errorf("Patch() does not contain %q:\n%s", want, patch.String())
+		}
+	}
+}
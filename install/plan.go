@@ -0,0 +1,410 @@
+package install
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/networkteam/shry/config"
+	"github.com/networkteam/shry/diff"
+	"github.com/networkteam/shry/merge"
+	"github.com/networkteam/shry/registry"
+	"github.com/networkteam/shry/template"
+)
+
+// Action is what happens to a destination file when a plan is applied
+type Action int
+
+const (
+	// ActionCreate creates a new file
+	ActionCreate Action = iota
+	// ActionOverwrite replaces an existing file
+	ActionOverwrite
+	// ActionSkip keeps an existing file
+	ActionSkip
+	// ActionUnchanged keeps an existing file with the same content
+	ActionUnchanged
+	// ActionMerge replaces an existing file with content merged from the existing and new content
+	ActionMerge
+	// ActionInsert inserts a snippet into an existing file
+	ActionInsert
+)
+
+// Change is a file of a component to write to the project
+type Change struct {
+	// Component the file belongs to
+	Component *config.Component
+	// Src file relative to the component directory
+	Src string
+	// Dst file relative to the project directory (with resolved variables)
+	Dst string
+	// Content to write (with resolved variables)
+	Content []byte
+	// Existing content of the destination file, nil if it does not exist.
+	// For snippets inserted into a file written by another change, it is the content of that change.
+	Existing []byte
+	// Insert is set if the file is a snippet inserted into the destination
+	Insert *config.Insert
+	// Merged content of the existing and new content, set if the file has a merge strategy and the existing file differs
+	Merged []byte
+	// MergeChanges are the semantic changes of Merged to the existing content
+	MergeChanges []merge.Change
+	// Action to apply, existing files with other content must be decided before applying
+	Action Action
+}
+
+// Conflicting returns true if the destination exists with other content and the action must be decided
+func (c *Change) Conflicting() bool {
+	return c.Existing != nil && c.Action != ActionUnchanged && c.Insert == nil
+}
+
+// writes returns true if the change writes the destination file
+func (c *Change) writes() bool {
+	return c.Action == ActionCreate || c.Action == ActionOverwrite || c.Action == ActionMerge || c.Action == ActionInsert
+}
+
+// Plan is the set of changes to add components to a project.
+// All files are read and resolved when the plan is created, nothing is written before Apply.
+type Plan struct {
+	// ProjectDir is the directory destinations are relative to
+	ProjectDir string
+	// Components to add, dependencies first
+	Components []*config.Component
+	// Changes of all components in order
+	Changes []*Change
+}
+
+// NewPlan reads and resolves the files of all components and checks existing destination files.
+// Existing files with other content default to ActionSkip until decided otherwise.
+// It is an error if two components write different content to the same destination,
+// but snippets can be inserted into files of other components.
+func NewPlan(reg *registry.Registry, projectConfig *config.ProjectConfig, components []*config.Component) (*Plan, error) {
+	plan := &Plan{
+		ProjectDir: projectConfig.ProjectDir,
+		Components: components,
+	}
+
+	changesByDst := make(map[string]*Change)
+	for _, component := range components {
+		// Resolve files and verify variables, default variables of the component apply if the project does not define them
+		variables := component.ResolveVariables(projectConfig.Variables)
+		resolvedFiles, err := component.ResolveFiles(variables)
+		if err != nil {
+			return nil, fmt.Errorf("component %s: %w", component.Name, err)
+		}
+
+		for _, file := range resolvedFiles {
+			content, err := readContent(reg, component, file.Src, variables)
+			if err != nil {
+				return nil, err
+			}
+
+			change := &Change{
+				Component: component,
+				Src:       file.Src,
+				Dst:       filepath.Clean(file.Dst),
+				Content:   content,
+				Action:    ActionCreate,
+				Insert:    file.Insert,
+			}
+			strategy := merge.Strategy(file.Merge)
+
+			if change.Insert != nil {
+				if err := plan.insert(change, changesByDst[change.Dst]); err != nil {
+					return nil, fmt.Errorf("component %s: %w", component.Name, err)
+				}
+				changesByDst[change.Dst] = change
+				plan.Changes = append(plan.Changes, change)
+				continue
+			}
+
+			// Detect components writing the same destination
+			if other, exists := changesByDst[change.Dst]; exists {
+				if other.Insert != nil {
+					return nil, fmt.Errorf("destination conflict: %s is written by component %s after a snippet of component %s was inserted", change.Dst, component.Name, other.Component.Name)
+				}
+				if !bytes.Equal(other.Content, change.Content) {
+					return nil, fmt.Errorf("destination conflict: %s is written by components %s and %s", change.Dst, other.Component.Name, component.Name)
+				}
+				continue
+			}
+			changesByDst[change.Dst] = change
+
+			// Check for existing files
+			existing, err := os.ReadFile(plan.path(change))
+			switch {
+			case errors.Is(err, os.ErrNotExist):
+			case err != nil:
+				return nil, fmt.Errorf("reading existing file %s: %w", change.Dst, err)
+			default:
+				change.Existing = existing
+				change.Action = ActionSkip
+				if bytes.Equal(existing, change.Content) {
+					change.Action = ActionUnchanged
+					break
+				}
+
+				// Merge structured files, the existing file is unchanged if it already contains everything
+				if strategy != "" {
+					merged, mergeChanges, err := merge.Merge(strategy, change.Dst, existing, change.Content)
+					if err != nil {
+						return nil, fmt.Errorf("merging %s into %s: %w", change.Src, change.Dst, err)
+					}
+					if len(mergeChanges) == 0 {
+						change.Content = existing
+						change.Action = ActionUnchanged
+						break
+					}
+					change.Merged = merged
+					change.MergeChanges = mergeChanges
+				}
+			}
+
+			plan.Changes = append(plan.Changes, change)
+		}
+	}
+
+	return plan, nil
+}
+
+// WrittenComponents returns the components with files that are created, overwritten or merged
+func (p *Plan) WrittenComponents() []*config.Component {
+	var components []*config.Component
+	for _, component := range p.Components {
+		for _, change := range p.Changes {
+			if change.Component == component && change.writes() {
+				components = append(components, component)
+				break
+			}
+		}
+	}
+	return components
+}
+
+// insert resolves the content of a change inserting a snippet.
+// The snippet is inserted into the content of a previous change of the destination or the existing file.
+func (p *Plan) insert(change *Change, previous *Change) error {
+	snippet := string(change.Content)
+
+	markers, err := newSnippetMarkers(change.Component.Name, change.Src, change.Dst, change.Insert)
+	if err != nil {
+		return fmt.Errorf("inserting snippet %s: %w", change.Src, err)
+	}
+
+	switch {
+	case previous != nil:
+		if previous.Conflicting() {
+			return fmt.Errorf("cannot insert snippet into %s, the existing file conflicts with component %s", change.Dst, previous.Component.Name)
+		}
+		change.Existing = previous.Content
+	default:
+		existing, err := os.ReadFile(p.path(change))
+		switch {
+		case errors.Is(err, os.ErrNotExist):
+			// A missing destination is created from the snippet, unless it must be inserted at an anchor
+			if change.Insert.After != "" || change.Insert.Before != "" {
+				return fmt.Errorf("inserting snippet %s: destination %s does not exist", change.Src, change.Dst)
+			}
+			content, _, err := insertSnippet("", snippet, change.Insert, markers)
+			if err != nil {
+				return fmt.Errorf("inserting snippet %s into %s: %w", change.Src, change.Dst, err)
+			}
+			change.Content = []byte(content)
+			return nil
+		case err != nil:
+			return fmt.Errorf("reading existing file %s: %w", change.Dst, err)
+		}
+		change.Existing = existing
+	}
+
+	content, inserted, err := insertSnippet(string(change.Existing), snippet, change.Insert, markers)
+	if err != nil {
+		return fmt.Errorf("inserting snippet %s into %s: %w", change.Src, change.Dst, err)
+	}
+
+	change.Content = []byte(content)
+	change.Action = ActionUnchanged
+	if inserted {
+		change.Action = ActionInsert
+	}
+	return nil
+}
+
+// Conflicts returns the changes of existing files with other content
+func (p *Plan) Conflicts() []*Change {
+	var conflicts []*Change
+	for _, change := range p.Changes {
+		if change.Conflicting() {
+			conflicts = append(conflicts, change)
+		}
+	}
+	return conflicts
+}
+
+// Apply writes all created and overwritten files.
+// Files are staged as temporary files next to their destination and renamed into place,
+// if anything fails all files written so far are restored from a backup journal.
+func (p *Plan) Apply() (err error) {
+	j, err := newJournal()
+	if err != nil {
+		return err
+	}
+
+	// Stage all files before replacing any destination
+	staged := make(map[*Change]string)
+	defer func() {
+		for _, tmpPath := range staged {
+			_ = os.Remove(tmpPath)
+		}
+		if err != nil {
+			if rollbackErr := j.rollback(); rollbackErr != nil {
+				err = errors.Join(err, rollbackErr)
+			}
+		}
+	}()
+
+	for _, change := range p.Changes {
+		if !change.writes() {
+			continue
+		}
+
+		tmpPath, err := p.stage(j, change)
+		if err != nil {
+			return err
+		}
+		staged[change] = tmpPath
+	}
+
+	// Replace destinations atomically, recording previous files in the journal
+	for _, change := range p.Changes {
+		tmpPath, ok := staged[change]
+		if !ok {
+			continue
+		}
+
+		dstPath := p.path(change)
+		if err := j.record(dstPath); err != nil {
+			return err
+		}
+		if err := os.Rename(tmpPath, dstPath); err != nil {
+			return fmt.Errorf("writing destination file %s: %w", change.Dst, err)
+		}
+		delete(staged, change)
+	}
+
+	return j.commit()
+}
+
+// stage writes the content of the change to a temporary file in the destination directory
+func (p *Plan) stage(j *journal, change *Change) (string, error) {
+	dstPath := p.path(change)
+
+	// Create destination directory if needed
+	if err := j.mkdirAll(filepath.Dir(dstPath)); err != nil {
+		return "", err
+	}
+
+	// Keep the mode of an existing file
+	mode := os.FileMode(0644)
+	if info, err := os.Stat(dstPath); err == nil {
+		mode = info.Mode().Perm()
+	}
+
+	tmpFile, err := os.CreateTemp(filepath.Dir(dstPath), "."+filepath.Base(dstPath)+".shry-*")
+	if err != nil {
+		return "", fmt.Errorf("staging %s: %w", change.Dst, err)
+	}
+	if _, err := tmpFile.Write(change.Content); err != nil {
+		tmpFile.Close()
+		os.Remove(tmpFile.Name())
+		return "", fmt.Errorf("staging %s: %w", change.Dst, err)
+	}
+	if err := tmpFile.Close(); err != nil {
+		os.Remove(tmpFile.Name())
+		return "", fmt.Errorf("staging %s: %w", change.Dst, err)
+	}
+	if err := os.Chmod(tmpFile.Name(), mode); err != nil {
+		os.Remove(tmpFile.Name())
+		return "", fmt.Errorf("staging %s: %w", change.Dst, err)
+	}
+
+	return tmpFile.Name(), nil
+}
+
+// Patch writes a unified diff of all written files instead of applying the plan.
+// Destinations written by several changes are combined into one diff,
+// including manifest updates returned by the ManifestUpdates method of the plan.
+func (p *Plan) Patch(w io.Writer, manifests ...*ManifestUpdate) error {
+	var dsts []string
+	existing := make(map[string][]byte)
+	content := make(map[string][]byte)
+	for _, change := range p.Changes {
+		if !change.writes() {
+			continue
+		}
+		if _, exists := content[change.Dst]; !exists {
+			existing[change.Dst] = change.Existing
+			dsts = append(dsts, change.Dst)
+		}
+		content[change.Dst] = change.Content
+	}
+
+	// Manifest updates are based on the content of the plan, so they replace it
+	for _, manifest := range manifests {
+		dst := filepath.Clean(manifest.Path)
+		if _, exists := content[dst]; !exists {
+			existing[dst] = manifest.Existing
+			dsts = append(dsts, dst)
+		}
+		content[dst] = manifest.Content
+	}
+
+	for _, dst := range dsts {
+		if err := diff.WritePatch(w, dst, existing[dst], content[dst]); err != nil {
+			return fmt.Errorf("writing patch of %s: %w", dst, err)
+		}
+	}
+	return nil
+}
+
+// ManifestUpdates adds the packages to the manifests like ManifestUpdates, but on top of the content
+// the plan writes to a manifest. The updates can be passed to Patch to get one diff per file.
+func (p *Plan) ManifestUpdates(packages []Package) ([]*ManifestUpdate, []Package, error) {
+	return manifestUpdates(packages, func(path string) ([]byte, error) {
+		var written *Change
+		for _, change := range p.Changes {
+			if change.writes() && change.Dst == filepath.Clean(path) {
+				written = change
+			}
+		}
+		if written != nil {
+			return written.Content, nil
+		}
+		return readManifest(p.ProjectDir, path)
+	})
+}
+
+// readContent reads a source file of the component and resolves the variables in its content
+func readContent(reg *registry.Registry, component *config.Component, src string, variables map[string]any) ([]byte, error) {
+	srcPath := filepath.Join(component.Path, src)
+	srcContent, err := reg.ReadFile(srcPath)
+	if err != nil {
+		return nil, fmt.Errorf("reading source file %s: %w", srcPath, err)
+	}
+
+	// Substitute variables in content
+	content, err := template.Resolve(string(srcContent), variables)
+	if err != nil {
+		return nil, fmt.Errorf("resolving variables in %s: %w", srcPath, err)
+	}
+
+	return []byte(content), nil
+}
+
+// path returns the absolute destination path of the change
+func (p *Plan) path(change *Change) string {
+	return filepath.Join(p.ProjectDir, change.Dst)
+}
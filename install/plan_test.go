@@ -0,0 +1,149 @@
+package install_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/networkteam/shry/config"
+	"github.com/networkteam/shry/install"
+	"github.com/networkteam/shry/registry"
+)
+
+func TestPlan(t *testing.T) {
+	reg := newTestRegistry(t, map[string]string{
+		"neos/button/shry.yaml":     "name: button\nplatform: neos\nfiles:\n  - src: Button.fusion\n    dst: \"{{package}}/Button.fusion\"\n",
+		"neos/button/Button.fusion": "prototype({{package}}:Button)\n",
+		"neos/card/shry.yaml":       "name: card\nplatform: neos\ndependencies: [button]\nfiles:\n  - src: Card.fusion\n    dst: \"{{package}}/Card.fusion\"\n",
+		"neos/card/Card.fusion":     "prototype({{package}}:Card)\n",
+		"neos/teaser/shry.yaml":     "name: teaser\nplatform: neos\nfiles:\n  - src: Card.fusion\n    dst: \"{{package}}/Card.fusion\"\n",
+		"neos/teaser/Card.fusion":   "prototype({{package}}:Teaser)\n",
+	})
+
+	projectDir := t.TempDir()
+	projectConfig := &config.ProjectConfig{
+		ProjectDir: projectDir,
+		Platform:   "neos",
+		Variables:  map[string]any{"package": "Acme.Site"},
+	}
+	writeFile(t, filepath.Join(projectDir, "Acme.Site/Button.fusion"), "prototype(Acme.Site:Button)\n")
+	writeFile(t, filepath.Join(projectDir, "Acme.Site/Card.fusion"), "// modified\n")
+
+	t.Run("conflicting destinations", func(t *testing.T) {
+		components, err := reg.ResolveComponents("neos", []string{"card", "teaser"})
+		if err != nil {
+			t.Fatalf("ResolveComponents() unexpected error: %v", err)
+		}
+		if _, err := install.NewPlan(reg, projectConfig, components); err == nil {
+			t.Error("NewPlan() expected destination conflict error, got nil")
+		}
+	})
+
+	t.Run("apply", func(t *testing.T) {
+		components, err := reg.ResolveComponents("neos", []string{"card"})
+		if err != nil {
+			t.Fatalf("ResolveComponents() unexpected error: %v", err)
+		}
+		plan, err := install.NewPlan(reg, projectConfig, components)
+		if err != nil {
+			t.Fatalf("NewPlan() unexpected error: %v", err)
+		}
+
+		if len(plan.Changes) != 2 {
+			t.Fatalf("NewPlan() changes = %d, want 2", len(plan.Changes))
+		}
+		if plan.Changes[0].Action != install.ActionUnchanged {
+			t.Errorf("unchanged file action = %v, want %v", plan.Changes[0].Action, install.ActionUnchanged)
+		}
+
+		conflicts := plan.Conflicts()
+		if len(conflicts) != 1 || conflicts[0].Dst != filepath.FromSlash("Acme.Site/Card.fusion") {
+			t.Fatalf("Conflicts() = %v, want Acme.Site/Card.fusion", conflicts)
+		}
+		conflicts[0].Action = install.ActionOverwrite
+
+		if err := plan.Apply(); err != nil {
+			t.Fatalf("Apply() unexpected error: %v", err)
+		}
+
+		content, err := os.ReadFile(filepath.Join(projectDir, "Acme.Site/Card.fusion"))
+		if err != nil {
+			t.Fatalf("reading applied file: %v", err)
+		}
+		if string(content) != "prototype(Acme.Site:Card)\n" {
+			t.Errorf("applied content = %q", content)
+		}
+	})
+}
+
+func newTestRegistry(t *testing.T, files map[string]string) *registry.Registry {
+	t.Helper()
+
+	registryDir := t.TempDir()
+	for name, content := range files {
+		writeFile(t, filepath.Join(registryDir, name), content)
+	}
+
+	cache, err := registry.NewCache(t.TempDir(), &config.GlobalConfig{})
+	if err != nil {
+		t.Fatalf("NewCache() unexpected error: %v", err)
+	}
+	reg, err := cache.GetRegistry(registryDir, "", t.TempDir())
+	if err != nil {
+		t.Fatalf("GetRegistry() unexpected error: %v", err)
+	}
+	return reg
+}
+
+func writeFile(t *testing.T, path string, content string) {
+	t.Helper()
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatalf("creating directory: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("writing %s: %v", path, err)
+	}
+}
+
+func TestApplyRollsBackOnFailure(t *testing.T) {
+	projectDir := t.TempDir()
+	writeFile(t, filepath.Join(projectDir, "Existing.fusion"), "original\n")
+	// A non-empty directory at a destination makes replacing it fail after other files were written
+	writeFile(t, filepath.Join(projectDir, "Blocked/file"), "")
+
+	component := &config.Component{Name: "button"}
+	plan := &install.Plan{
+		ProjectDir: projectDir,
+		Components: []*config.Component{component},
+		Changes: []*install.Change{
+			{Component: component, Dst: "Existing.fusion", Content: []byte("new\n"), Existing: []byte("original\n"), Action: install.ActionOverwrite},
+			{Component: component, Dst: filepath.Join("New", "Button.fusion"), Content: []byte("new\n"), Action: install.ActionCreate},
+			{Component: component, Dst: "Blocked", Content: []byte("new\n"), Action: install.ActionCreate},
+		},
+	}
+
+	if err := plan.Apply(); err == nil {
+		t.Fatal("Apply() expected error, got nil")
+	}
+
+	content, err := os.ReadFile(filepath.Join(projectDir, "Existing.fusion"))
+	if err != nil {
+		t.Fatalf("reading restored file: %v", err)
+	}
+	if string(content) != "original\n" {
+		t.Errorf("restored content = %q, want %q", content, "original\n")
+	}
+
+	if _, err := os.Stat(filepath.Join(projectDir, "New")); !os.IsNotExist(err) {
+		t.Errorf("created directory New was not removed: %v", err)
+	}
+
+	entries, err := os.ReadDir(projectDir)
+	if err != nil {
+		t.Fatalf("reading project directory: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Errorf("project directory has %d entries after rollback, want 2 (no staged files left)", len(entries))
+	}
+}
@@ -0,0 +1,127 @@
+package install
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/networkteam/shry/config"
+)
+
+// Removal is the set of files to delete and snippets to remove to remove components from a project
+type Removal struct {
+	// ProjectDir is the directory files are relative to
+	ProjectDir string
+	// Components to remove
+	Components []*config.Component
+	// Files of the components that exist in the project, relative to the project directory
+	Files []string
+	// Snippets are files with inserted snippets of the components, with the content after removing them
+	Snippets []*SnippetRemoval
+}
+
+// SnippetRemoval is a file with the snippets of components removed
+type SnippetRemoval struct {
+	// Dst file relative to the project directory
+	Dst string
+	// Content without the snippets
+	Content []byte
+}
+
+// NewRemoval resolves the destination files of the components and collects the existing ones.
+// Snippets are removed between their markers from the files they were inserted into, if they are still present.
+// A file that is empty after removing snippets was created by them and is deleted.
+func NewRemoval(projectConfig *config.ProjectConfig, components []*config.Component) (*Removal, error) {
+	removal := &Removal{
+		ProjectDir: projectConfig.ProjectDir,
+		Components: components,
+	}
+
+	snippetsByDst := make(map[string]*SnippetRemoval)
+	for _, component := range components {
+		variables := component.ResolveVariables(projectConfig.Variables)
+		resolvedFiles, err := component.ResolveFiles(variables)
+		if err != nil {
+			return nil, fmt.Errorf("component %s: %w", component.Name, err)
+		}
+
+		for _, file := range resolvedFiles {
+			dst := filepath.Clean(file.Dst)
+			if slices.Contains(removal.Files, dst) {
+				continue
+			}
+
+			existing, err := os.ReadFile(filepath.Join(removal.ProjectDir, dst))
+			switch {
+			case errors.Is(err, os.ErrNotExist):
+				continue
+			case err != nil:
+				return nil, fmt.Errorf("reading file %s: %w", dst, err)
+			}
+
+			if file.Insert == nil {
+				removal.Files = append(removal.Files, dst)
+				continue
+			}
+
+			markers, err := newSnippetMarkers(component.Name, file.Src, dst, file.Insert)
+			if err != nil {
+				return nil, fmt.Errorf("component %s: %w", component.Name, err)
+			}
+
+			// Several snippets can be removed from the same file
+			snippetRemoval, exists := snippetsByDst[dst]
+			if !exists {
+				snippetRemoval = &SnippetRemoval{Dst: dst, Content: existing}
+			}
+			content, removed := removeSnippet(string(snippetRemoval.Content), markers)
+			if !removed {
+				continue
+			}
+			snippetRemoval.Content = []byte(content)
+			if !exists {
+				snippetsByDst[dst] = snippetRemoval
+				removal.Snippets = append(removal.Snippets, snippetRemoval)
+			}
+		}
+	}
+
+	// Files with nothing left but snippets are deleted, snippets of deleted files do not need to be removed
+	for _, snippetRemoval := range removal.Snippets {
+		if len(bytes.TrimSpace(snippetRemoval.Content)) == 0 && !slices.Contains(removal.Files, snippetRemoval.Dst) {
+			removal.Files = append(removal.Files, snippetRemoval.Dst)
+		}
+	}
+	removal.Snippets = slices.DeleteFunc(removal.Snippets, func(s *SnippetRemoval) bool {
+		return slices.Contains(removal.Files, s.Dst)
+	})
+
+	return removal, nil
+}
+
+// Apply removes the snippets, deletes the files and all directories that are empty afterwards
+func (r *Removal) Apply() error {
+	for _, snippet := range r.Snippets {
+		if err := os.WriteFile(filepath.Join(r.ProjectDir, snippet.Dst), snippet.Content, 0644); err != nil {
+			return fmt.Errorf("removing snippet from %s: %w", snippet.Dst, err)
+		}
+	}
+
+	for _, file := range r.Files {
+		path := filepath.Join(r.ProjectDir, file)
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("removing %s: %w", file, err)
+		}
+
+		// Remove parent directories up to the project directory while they are empty
+		for dir := filepath.Dir(path); dir != r.ProjectDir && dir != filepath.Dir(dir); dir = filepath.Dir(dir) {
+			if err := os.Remove(dir); err != nil {
+				break
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,216 @@
+package install
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/networkteam/shry/config"
+)
+
+// commentSyntaxes maps file extensions to the comment syntax used for snippet markers
+var commentSyntaxes = map[string]string{
+	".js":     "//",
+	".jsx":    "//",
+	".mjs":    "//",
+	".cjs":    "//",
+	".ts":     "//",
+	".tsx":    "//",
+	".go":     "//",
+	".php":    "//",
+	".java":   "//",
+	".kt":     "//",
+	".swift":  "//",
+	".rs":     "//",
+	".c":      "//",
+	".h":      "//",
+	".cpp":    "//",
+	".cs":     "//",
+	".scss":   "//",
+	".less":   "//",
+	".fusion": "//",
+	".css":    "/* */",
+	".yaml":   "#",
+	".yml":    "#",
+	".toml":   "#",
+	".sh":     "#",
+	".py":     "#",
+	".rb":     "#",
+	".env":    "#",
+	".conf":   "#",
+	".ini":    ";",
+	".html":   "<!-- -->",
+	".xml":    "<!-- -->",
+	".xlf":    "<!-- -->",
+	".xliff":  "<!-- -->",
+	".svg":    "<!-- -->",
+	".vue":    "<!-- -->",
+	".md":     "<!-- -->",
+}
+
+// snippetMarkers are the comment lines wrapping a snippet inserted by a component.
+// Only lines between the markers are removed again, so content of the user is never touched.
+// The markers name the component and the snippet source, so a component can insert several snippets into one file.
+type snippetMarkers struct {
+	begin string
+	end   string
+}
+
+// newSnippetMarkers returns the markers for the snippet src of the component in the destination.
+// The comment syntax is taken from insert or derived from the file extension of the destination.
+func newSnippetMarkers(componentName string, src string, dst string, insert *config.Insert) (snippetMarkers, error) {
+	comment := ""
+	if insert != nil {
+		comment = insert.Comment
+	}
+	if comment == "" {
+		comment = commentSyntaxes[strings.ToLower(filepath.Ext(dst))]
+	}
+	if comment == "" {
+		return snippetMarkers{}, fmt.Errorf("no comment syntax known for snippet markers in %s, set insert.comment", dst)
+	}
+
+	prefix, suffix, _ := strings.Cut(comment, " ")
+	if suffix != "" {
+		suffix = " " + strings.TrimSpace(suffix)
+	}
+	name := componentName + "/" + filepath.ToSlash(filepath.Clean(src))
+	return snippetMarkers{
+		begin: fmt.Sprintf("%s shry:begin %s%s", prefix, name, suffix),
+		end:   fmt.Sprintf("%s shry:end %s%s", prefix, name, suffix),
+	}, nil
+}
+
+// insertSnippet inserts the snippet lines wrapped in markers into the existing content at the anchor of insert.
+// A marked snippet is updated in place. It returns false if the content is unchanged, also if the snippet lines
+// are already present without markers, so inserting is idempotent.
+func insertSnippet(existing, snippet string, insert *config.Insert, markers snippetMarkers) (string, bool, error) {
+	lines := splitContentLines(existing)
+	snippetLines := splitContentLines(snippet)
+	if len(snippetLines) == 0 {
+		return existing, false, nil
+	}
+
+	if start, end := findMarkedSnippet(lines, markers); start >= 0 {
+		if equalLines(lines[start+1:end], snippetLines) {
+			return existing, false, nil
+		}
+		result := make([]string, 0, len(lines)+len(snippetLines))
+		result = append(result, lines[:start+1]...)
+		result = append(result, snippetLines...)
+		result = append(result, lines[end:]...)
+		return joinContentLines(result), true, nil
+	}
+	if findSnippet(lines, snippetLines) >= 0 {
+		return existing, false, nil
+	}
+
+	pos := len(lines)
+	switch {
+	case insert.After != "":
+		idx := findAnchor(lines, insert.After)
+		if idx < 0 {
+			return "", false, fmt.Errorf("anchor %q not found", insert.After)
+		}
+		pos = idx + 1
+	case insert.Before != "":
+		idx := findAnchor(lines, insert.Before)
+		if idx < 0 {
+			return "", false, fmt.Errorf("anchor %q not found", insert.Before)
+		}
+		pos = idx
+	}
+
+	result := make([]string, 0, len(lines)+len(snippetLines)+2)
+	result = append(result, lines[:pos]...)
+	result = append(result, markers.begin)
+	result = append(result, snippetLines...)
+	result = append(result, markers.end)
+	result = append(result, lines[pos:]...)
+	return joinContentLines(result), true, nil
+}
+
+// removeSnippet removes all snippets wrapped in the markers including the markers from the content.
+// It returns false if no marked snippet is present.
+func removeSnippet(content string, markers snippetMarkers) (string, bool) {
+	lines := splitContentLines(content)
+	removed := false
+	for {
+		start, end := findMarkedSnippet(lines, markers)
+		if start < 0 {
+			break
+		}
+		lines = append(lines[:start:start], lines[end+1:]...)
+		removed = true
+	}
+	if !removed {
+		return content, false
+	}
+	return joinContentLines(lines), true
+}
+
+// findMarkedSnippet returns the indexes of the begin and end marker lines of a snippet, or -1
+func findMarkedSnippet(lines []string, markers snippetMarkers) (int, int) {
+	for i, line := range lines {
+		if strings.TrimSpace(line) != markers.begin {
+			continue
+		}
+		for j := i + 1; j < len(lines); j++ {
+			if strings.TrimSpace(lines[j]) == markers.end {
+				return i, j
+			}
+		}
+		break
+	}
+	return -1, -1
+}
+
+// findSnippet returns the index of the first line of the snippet lines in lines, or -1
+func findSnippet(lines, snippetLines []string) int {
+	for i := 0; i+len(snippetLines) <= len(lines); i++ {
+		if equalLines(lines[i:i+len(snippetLines)], snippetLines) {
+			return i
+		}
+	}
+	return -1
+}
+
+// equalLines compares lines ignoring trailing whitespace
+func equalLines(lines, other []string) bool {
+	if len(lines) != len(other) {
+		return false
+	}
+	for i := range lines {
+		if strings.TrimRight(lines[i], " \t\r") != strings.TrimRight(other[i], " \t\r") {
+			return false
+		}
+	}
+	return true
+}
+
+// findAnchor returns the index of the first line containing the anchor, or -1
+func findAnchor(lines []string, anchor string) int {
+	for i, line := range lines {
+		if strings.Contains(line, anchor) {
+			return i
+		}
+	}
+	return -1
+}
+
+// splitContentLines splits content into lines without line breaks
+func splitContentLines(content string) []string {
+	content = strings.TrimSuffix(content, "\n")
+	if content == "" {
+		return nil
+	}
+	return strings.Split(content, "\n")
+}
+
+// joinContentLines joins lines with a trailing line break
+func joinContentLines(lines []string) string {
+	if len(lines) == 0 {
+		return ""
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
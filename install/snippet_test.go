@@ -0,0 +1,225 @@
+package install_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/networkteam/shry/config"
+	"github.com/networkteam/shry/install"
+)
+
+func TestSnippets(t *testing.T) {
+	reg := newTestRegistry(t, map[string]string{
+		"react/button/shry.yaml":       "name: button\nplatform: react\nfiles:\n  - src: index.ts\n    dst: index.ts\n    insert:\n      after: \"// components\"\n",
+		"react/button/index.ts":        "export * from './Button'\n",
+		"react/buttons/shry.yaml":      "name: buttons\nplatform: react\ndependencies: [button]\nfiles:\n  - src: index.ts\n    dst: index.ts\n    insert: {}\n",
+		"react/buttons/index.ts":       "export * from './ButtonGroup'\n",
+		"react/missing/shry.yaml":      "name: missing\nplatform: react\nfiles:\n  - src: index.ts\n    dst: index.ts\n    insert:\n      before: \"// unknown\"\n",
+		"react/missing/index.ts":       "export * from './Missing'\n",
+		"react/settings/shry.yaml":     "name: settings\nplatform: react\nfiles:\n  - src: Settings.yaml\n    dst: Settings.yaml\n    insert: {}\n",
+		"react/settings/Settings.yaml": "{{package}}:\n  enabled: true\n",
+	})
+
+	projectDir := t.TempDir()
+	projectConfig := &config.ProjectConfig{
+		ProjectDir: projectDir,
+		Platform:   "react",
+		Variables:  map[string]any{"package": "Acme.Site"},
+	}
+	original := "import React from 'react'\n// components\nexport * from './Card'\n"
+	writeFile(t, filepath.Join(projectDir, "index.ts"), original)
+
+	add := func(t *testing.T, names ...string) *install.Plan {
+		t.Helper()
+		components, err := reg.ResolveComponents("react", names)
+		if err != nil {
+			t.Fatalf("ResolveComponents() unexpected error: %v", err)
+		}
+		plan, err := install.NewPlan(reg, projectConfig, components)
+		if err != nil {
+			t.Fatalf("NewPlan() unexpected error: %v", err)
+		}
+		if len(plan.Conflicts()) != 0 {
+			t.Fatalf("Conflicts() = %v, want none", plan.Conflicts())
+		}
+		if err := plan.Apply(); err != nil {
+			t.Fatalf("Apply() unexpected error: %v", err)
+		}
+		return plan
+	}
+	assertContent := func(t *testing.T, name, want string) {
+		t.Helper()
+		content, err := os.ReadFile(filepath.Join(projectDir, name))
+		if err != nil {
+			t.Fatalf("reading %s: %v", name, err)
+		}
+		if string(content) != want {
+			t.Errorf("%s = %q, want %q", name, content, want)
+		}
+	}
+
+	inserted := "import React from 'react'\n// components\n// shry:begin button/index.ts\nexport * from './Button'\n// shry:end button/index.ts\nexport * from './Card'\n" +
+		"// shry:begin buttons/index.ts\nexport * from './ButtonGroup'\n// shry:end buttons/index.ts\n"
+
+	t.Run("insert into the same file", func(t *testing.T) {
+		add(t, "buttons")
+		assertContent(t, "index.ts", inserted)
+	})
+
+	t.Run("insert again is unchanged", func(t *testing.T) {
+		plan := add(t, "buttons")
+		for _, change := range plan.Changes {
+			if change.Action != install.ActionUnchanged {
+				t.Errorf("change %s action = %v, want %v", change.Src, change.Action, install.ActionUnchanged)
+			}
+		}
+		assertContent(t, "index.ts", inserted)
+	})
+
+	t.Run("missing destination is created", func(t *testing.T) {
+		add(t, "settings")
+		assertContent(t, "Settings.yaml", "# shry:begin settings/Settings.yaml\nAcme.Site:\n  enabled: true\n# shry:end settings/Settings.yaml\n")
+	})
+
+	t.Run("missing anchor", func(t *testing.T) {
+		components, err := reg.ResolveComponents("react", []string{"missing"})
+		if err != nil {
+			t.Fatalf("ResolveComponents() unexpected error: %v", err)
+		}
+		if _, err := install.NewPlan(reg, projectConfig, components); err == nil {
+			t.Error("NewPlan() expected missing anchor error, got nil")
+		}
+	})
+
+	t.Run("remove snippets", func(t *testing.T) {
+		components, err := reg.ResolveComponents("react", []string{"buttons"})
+		if err != nil {
+			t.Fatalf("ResolveComponents() unexpected error: %v", err)
+		}
+		removal, err := install.NewRemoval(projectConfig, components)
+		if err != nil {
+			t.Fatalf("NewRemoval() unexpected error: %v", err)
+		}
+		if len(removal.Files) != 0 || len(removal.Snippets) != 1 {
+			t.Fatalf("NewRemoval() files = %v, snippets = %d, want only one snippet", removal.Files, len(removal.Snippets))
+		}
+		if err := removal.Apply(); err != nil {
+			t.Fatalf("Apply() unexpected error: %v", err)
+		}
+		assertContent(t, "index.ts", original)
+	})
+
+	t.Run("remove snippet that created the file", func(t *testing.T) {
+		components, err := reg.ResolveComponents("react", []string{"settings"})
+		if err != nil {
+			t.Fatalf("ResolveComponents() unexpected error: %v", err)
+		}
+		removal, err := install.NewRemoval(projectConfig, components)
+		if err != nil {
+			t.Fatalf("NewRemoval() unexpected error: %v", err)
+		}
+		if len(removal.Files) != 1 || len(removal.Snippets) != 0 {
+			t.Fatalf("NewRemoval() files = %v, snippets = %d, want only Settings.yaml", removal.Files, len(removal.Snippets))
+		}
+		if err := removal.Apply(); err != nil {
+			t.Fatalf("Apply() unexpected error: %v", err)
+		}
+		if _, err := os.Stat(filepath.Join(projectDir, "Settings.yaml")); !os.IsNotExist(err) {
+			t.Errorf("Settings.yaml still exists after removing its only snippet")
+		}
+	})
+}
+
+func TestSeveralSnippetsOfComponent(t *testing.T) {
+	reg := newTestRegistry(t, map[string]string{
+		"react/barrel/shry.yaml": "name: barrel\nplatform: react\nfiles:\n" +
+			"  - src: import.ts\n    dst: index.ts\n    insert:\n      after: \"// imports\"\n" +
+			"  - src: export.ts\n    dst: index.ts\n    insert:\n      after: \"// exports\"\n",
+		"react/barrel/import.ts": "import { Barrel } from './Barrel'\n",
+		"react/barrel/export.ts": "export { Barrel }\n",
+	})
+
+	projectDir := t.TempDir()
+	projectConfig := &config.ProjectConfig{
+		ProjectDir: projectDir,
+		Platform:   "react",
+	}
+	original := "// imports\n// exports\n"
+	writeFile(t, filepath.Join(projectDir, "index.ts"), original)
+
+	components, err := reg.ResolveComponents("react", []string{"barrel"})
+	if err != nil {
+		t.Fatalf("ResolveComponents() unexpected error: %v", err)
+	}
+	want := "// imports\n// shry:begin barrel/import.ts\nimport { Barrel } from './Barrel'\n// shry:end barrel/import.ts\n" +
+		"// exports\n// shry:begin barrel/export.ts\nexport { Barrel }\n// shry:end barrel/export.ts\n"
+
+	// Adding again must leave both snippets unchanged
+	for i := range 2 {
+		plan, err := install.NewPlan(reg, projectConfig, components)
+		if err != nil {
+			t.Fatalf("NewPlan() unexpected error: %v", err)
+		}
+		if err := plan.Apply(); err != nil {
+			t.Fatalf("Apply() unexpected error: %v", err)
+		}
+		content, err := os.ReadFile(filepath.Join(projectDir, "index.ts"))
+		if err != nil {
+			t.Fatalf("reading index.ts: %v", err)
+		}
+		if string(content) != want {
+			t.Errorf("index.ts after add %d = %q, want %q", i+1, content, want)
+		}
+	}
+
+	removal, err := install.NewRemoval(projectConfig, components)
+	if err != nil {
+		t.Fatalf("NewRemoval() unexpected error: %v", err)
+	}
+	if err := removal.Apply(); err != nil {
+		t.Fatalf("Apply() unexpected error: %v", err)
+	}
+	content, err := os.ReadFile(filepath.Join(projectDir, "index.ts"))
+	if err != nil {
+		t.Fatalf("reading index.ts: %v", err)
+	}
+	if string(content) != original {
+		t.Errorf("index.ts after remove = %q, want %q", content, original)
+	}
+}
+
+func TestSnippetsKeepLinesOfUser(t *testing.T) {
+	reg := newTestRegistry(t, map[string]string{
+		"react/button/shry.yaml": "name: button\nplatform: react\nfiles:\n  - src: index.ts\n    dst: index.ts\n    insert:\n      after: \"// components\"\n",
+		"react/button/index.ts":  "export * from './Button'\n",
+	})
+
+	projectDir := t.TempDir()
+	projectConfig := &config.ProjectConfig{
+		ProjectDir: projectDir,
+		Platform:   "react",
+	}
+	original := "// components\nexport * from './Button'\n"
+	writeFile(t, filepath.Join(projectDir, "index.ts"), original)
+
+	components, err := reg.ResolveComponents("react", []string{"button"})
+	if err != nil {
+		t.Fatalf("ResolveComponents() unexpected error: %v", err)
+	}
+	plan, err := install.NewPlan(reg, projectConfig, components)
+	if err != nil {
+		t.Fatalf("NewPlan() unexpected error: %v", err)
+	}
+	if action := plan.Changes[0].Action; action != install.ActionUnchanged {
+		t.Errorf("change action = %v, want %v", action, install.ActionUnchanged)
+	}
+
+	removal, err := install.NewRemoval(projectConfig, components)
+	if err != nil {
+		t.Fatalf("NewRemoval() unexpected error: %v", err)
+	}
+	if len(removal.Files) != 0 || len(removal.Snippets) != 0 {
+		t.Errorf("NewRemoval() files = %v, snippets = %d, want nothing to remove", removal.Files, len(removal.Snippets))
+	}
+}
@@ -0,0 +1,87 @@
+package merge
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// Strategy is the merge strategy of a file
+type Strategy string
+
+const (
+	// StrategyAuto selects the strategy by the file extension of the destination
+	StrategyAuto Strategy = "auto"
+	// StrategyYAML deep-merges YAML documents
+	StrategyYAML Strategy = "yaml"
+	// StrategyJSON deep-merges JSON documents
+	StrategyJSON Strategy = "json"
+	// StrategyXLIFF merges XLIFF trans-units by id
+	StrategyXLIFF Strategy = "xliff"
+)
+
+// ChangeKind is the kind of a semantic change
+type ChangeKind int
+
+const (
+	// Added is a value or element that did not exist
+	Added ChangeKind = iota
+	// Changed is a value or element that is replaced
+	Changed
+)
+
+// Change is a semantic change of a merge
+type Change struct {
+	Kind ChangeKind
+	// Path of the value, e.g. a key path or a trans-unit id
+	Path string
+	// Old value, empty for added values
+	Old string
+	// New value
+	New string
+}
+
+func (c Change) String() string {
+	if c.Kind == Added {
+		return fmt.Sprintf("+ %s: %s", c.Path, c.New)
+	}
+	return fmt.Sprintf("~ %s: %s → %s", c.Path, c.Old, c.New)
+}
+
+// Merge merges the upstream content into the existing content of the destination with the strategy.
+// Values of upstream replace existing values, everything else of the existing content is kept.
+// It returns the merged content and the semantic changes to the existing content.
+func Merge(strategy Strategy, dst string, existing, upstream []byte) ([]byte, []Change, error) {
+	resolved, err := resolveStrategy(strategy, dst)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	switch resolved {
+	case StrategyYAML:
+		return mergeYAML(existing, upstream)
+	case StrategyJSON:
+		return mergeJSON(existing, upstream)
+	default:
+		return mergeXLIFF(existing, upstream)
+	}
+}
+
+// resolveStrategy validates the strategy and selects it by extension for StrategyAuto
+func resolveStrategy(strategy Strategy, dst string) (Strategy, error) {
+	switch strategy {
+	case StrategyYAML, StrategyJSON, StrategyXLIFF:
+		return strategy, nil
+	case StrategyAuto, "":
+		switch strings.ToLower(filepath.Ext(dst)) {
+		case ".yaml", ".yml":
+			return StrategyYAML, nil
+		case ".json":
+			return StrategyJSON, nil
+		case ".xlf", ".xliff":
+			return StrategyXLIFF, nil
+		}
+		return "", fmt.Errorf("no merge strategy for %s, set yaml, json or xliff", dst)
+	}
+	return "", fmt.Errorf("unknown merge strategy %q", strategy)
+}
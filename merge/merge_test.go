@@ -0,0 +1,144 @@
+package merge_test
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/networkteam/shry/merge"
+)
+
+func TestMerge(t *testing.T) {
+	tests := []struct {
+		name        string
+		strategy    merge.Strategy
+		dst         string
+		existing    string
+		upstream    string
+		want        string
+		wantChanges []string
+		wantErr     bool
+	}{
+		{
+			name:     "yaml keeps comments and order",
+			strategy: merge.StrategyAuto,
+			dst:      "NodeTypes.Button.yaml",
+			existing: "# Button\n'Acme:Button':\n  superTypes:\n    'Neos.Neos:Content': true\n  ui:\n    label: My Button # customized\n    icon: icon-x\n",
+			upstream: "'Acme:Button':\n  superTypes:\n    'Neos.Neos:Content': true\n    'Acme:Mixin': true\n  ui:\n    label: Button\n",
+			want:     "# Button\n'Acme:Button':\n  superTypes:\n    'Neos.Neos:Content': true\n    'Acme:Mixin': true\n  ui:\n    label: Button # customized\n    icon: icon-x\n",
+			wantChanges: []string{
+				"+ Acme:Button.superTypes.Acme:Mixin: true",
+				"~ Acme:Button.ui.label: My Button → Button",
+			},
+		},
+		{
+			name:     "yaml without changes",
+			strategy: merge.StrategyYAML,
+			dst:      "Settings",
+			existing: "a:\n  b: 1 # one\n  c: 2\n",
+			upstream: "a:\n  b: 1\n",
+			want:     "a:\n  b: 1 # one\n  c: 2\n",
+		},
+		{
+			name:        "yaml keeps later documents",
+			strategy:    merge.StrategyYAML,
+			dst:         "Settings.yaml",
+			existing:    "a: 1\n---\nb: 2\n",
+			upstream:    "c: 3\n",
+			want:        "a: 1\nc: 3\n---\nb: 2\n",
+			wantChanges: []string{"+ c: 3"},
+		},
+		{
+			name:     "yaml with multiple upstream documents",
+			strategy: merge.StrategyYAML,
+			dst:      "Settings.yaml",
+			existing: "a: 1\n",
+			upstream: "c: 3\n---\nd: 4\n",
+			wantErr:  true,
+		},
+		{
+			name:     "json keeps indentation and combines lists",
+			strategy: merge.StrategyAuto,
+			dst:      "package.json",
+			existing: "{\n\t\"name\": \"site\",\n\t\"dependencies\": {\n\t\t\"react\": \"^18\"\n\t},\n\t\"files\": [\"dist\"]\n}\n",
+			upstream: "{\"dependencies\": {\"clsx\": \"^2\", \"react\": \"^18\"}, \"files\": [\"types\"], \"private\": true}",
+			want:     "{\n\t\"name\": \"site\",\n\t\"dependencies\": {\n\t\t\"react\": \"^18\",\n\t\t\"clsx\": \"^2\"\n\t},\n\t\"files\": [\n\t\t\"dist\",\n\t\t\"types\"\n\t],\n\t\"private\": true\n}\n",
+			wantChanges: []string{
+				"+ dependencies.clsx: ^2",
+				"+ files[]: types",
+				"+ private: true",
+			},
+		},
+		{
+			name:        "empty existing file",
+			strategy:    merge.StrategyJSON,
+			dst:         "config.json",
+			existing:    "",
+			upstream:    "{\"a\": 1}\n",
+			want:        "{\"a\": 1}\n",
+			wantChanges: []string{"+ .: {1 keys}"},
+		},
+		{
+			name:     "xliff merges trans-units by id",
+			strategy: merge.StrategyAuto,
+			dst:      "Main.xlf",
+			existing: "<?xml version=\"1.0\"?>\n<xliff version=\"1.2\">\n\t<file>\n\t\t<body>\n\t\t\t<trans-unit id=\"title\">\n\t\t\t\t<source>Title</source>\n\t\t\t</trans-unit>\n\t\t\t<trans-unit id=\"custom\"><source>Custom</source></trans-unit>\n\t\t</body>\n\t</file>\n</xliff>\n",
+			upstream: "<xliff version=\"1.2\"><file><body><trans-unit id=\"title\"><source>Headline</source></trans-unit><trans-unit id=\"label\"><source>Label</source></trans-unit></body></file></xliff>",
+			want:     "<?xml version=\"1.0\"?>\n<xliff version=\"1.2\">\n\t<file>\n\t\t<body>\n\t\t\t<trans-unit id=\"title\"><source>Headline</source></trans-unit>\n\t\t\t<trans-unit id=\"custom\"><source>Custom</source></trans-unit>\n\t\t\t<trans-unit id=\"label\"><source>Label</source></trans-unit>\n\t\t</body>\n\t</file>\n</xliff>\n",
+			wantChanges: []string{
+				"~ title: Title → Headline",
+				"+ label: Label",
+			},
+		},
+		{
+			name:     "xliff ignores whitespace",
+			strategy: merge.StrategyXLIFF,
+			dst:      "Main.xlf",
+			existing: "<xliff><file><body>\n  <trans-unit id=\"a\">\n    <source>A</source>\n  </trans-unit>\n</body></file></xliff>",
+			upstream: "<xliff><file><body><trans-unit id=\"a\"> <source>A</source> </trans-unit></body></file></xliff>",
+			want:     "<xliff><file><body>\n  <trans-unit id=\"a\">\n    <source>A</source>\n  </trans-unit>\n</body></file></xliff>",
+		},
+		{
+			name:     "unknown extension",
+			strategy: merge.StrategyAuto,
+			dst:      "README.md",
+			existing: "a",
+			upstream: "b",
+			wantErr:  true,
+		},
+		{
+			name:     "unknown strategy",
+			strategy: "toml",
+			dst:      "config.toml",
+			existing: "a",
+			upstream: "b",
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			merged, changes, err := merge.Merge(tt.strategy, tt.dst, []byte(tt.existing), []byte(tt.upstream))
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("Merge() expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Merge() unexpected error: %v", err)
+			}
+
+			if string(merged) != tt.want {
+				t.Errorf("Merge() content = %q, want %q", merged, tt.want)
+			}
+
+			var gotChanges []string
+			for _, change := range changes {
+				gotChanges = append(gotChanges, change.String())
+			}
+			if !slices.Equal(gotChanges, tt.wantChanges) {
+				t.Errorf("Merge() changes = %q, want %q", gotChanges, tt.wantChanges)
+			}
+		})
+	}
+}
@@ -0,0 +1,321 @@
+package merge
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// mergeYAML deep-merges the upstream YAML document into the existing one, keeping comments and key order.
+// If the existing file has several documents, the upstream document is merged into the first one
+// and the other documents are kept.
+func mergeYAML(existing, upstream []byte) ([]byte, []Change, error) {
+	existingDocs, upstreamRoot, err := parseDocuments(existing, upstream)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(existingDocs) == 0 {
+		return upstream, documentAdded(upstreamRoot), nil
+	}
+
+	var changes []Change
+	mergeNodes("", existingDocs[0].Content[0], upstreamRoot.Content[0], &changes)
+	if len(changes) == 0 {
+		return existing, nil, nil
+	}
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(len(detectIndent(existing, "  ")))
+	for _, doc := range existingDocs {
+		if err := enc.Encode(doc); err != nil {
+			return nil, nil, fmt.Errorf("encoding merged YAML: %w", err)
+		}
+	}
+	if err := enc.Close(); err != nil {
+		return nil, nil, fmt.Errorf("encoding merged YAML: %w", err)
+	}
+
+	return buf.Bytes(), changes, nil
+}
+
+// mergeJSON deep-merges the upstream JSON document into the existing one, keeping the key order.
+// JSON is a subset of YAML, so documents are merged as YAML nodes and written as JSON again.
+func mergeJSON(existing, upstream []byte) ([]byte, []Change, error) {
+	existingDocs, upstreamRoot, err := parseDocuments(existing, upstream)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(existingDocs) == 0 {
+		return upstream, documentAdded(upstreamRoot), nil
+	}
+	if len(existingDocs) > 1 {
+		return nil, nil, fmt.Errorf("existing file contains multiple documents")
+	}
+	existingRoot := existingDocs[0]
+
+	var changes []Change
+	mergeNodes("", existingRoot.Content[0], upstreamRoot.Content[0], &changes)
+	if len(changes) == 0 {
+		return existing, nil, nil
+	}
+
+	var buf bytes.Buffer
+	w := jsonWriter{buf: &buf, indent: detectIndent(existing, "  ")}
+	if err := w.write(existingRoot.Content[0], 0); err != nil {
+		return nil, nil, err
+	}
+	if bytes.HasSuffix(existing, []byte("\n")) {
+		buf.WriteString("\n")
+	}
+
+	return buf.Bytes(), changes, nil
+}
+
+// parseDocuments parses all documents of the existing file and the single upstream document.
+// Empty documents of the existing file are skipped, so no documents are returned for an empty file.
+func parseDocuments(existing, upstream []byte) ([]*yaml.Node, *yaml.Node, error) {
+	existingDocs, err := decodeDocuments(existing)
+	if err != nil {
+		return nil, nil, fmt.Errorf("parsing existing file: %w", err)
+	}
+	upstreamDocs, err := decodeDocuments(upstream)
+	if err != nil {
+		return nil, nil, fmt.Errorf("parsing component file: %w", err)
+	}
+	switch {
+	case len(upstreamDocs) == 0:
+		return nil, nil, fmt.Errorf("component file is empty")
+	case len(upstreamDocs) > 1:
+		return nil, nil, fmt.Errorf("component file contains multiple documents")
+	}
+	return existingDocs, upstreamDocs[0], nil
+}
+
+// decodeDocuments decodes all non-empty documents of a YAML stream
+func decodeDocuments(data []byte) ([]*yaml.Node, error) {
+	var docs []*yaml.Node
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	for {
+		var doc yaml.Node
+		err := dec.Decode(&doc)
+		if errors.Is(err, io.EOF) {
+			return docs, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(doc.Content) > 0 {
+			docs = append(docs, &doc)
+		}
+	}
+}
+
+// documentAdded is the change of an empty existing document
+func documentAdded(root *yaml.Node) []Change {
+	return []Change{{Kind: Added, Path: ".", New: summary(root.Content[0])}}
+}
+
+// mergeNodes merges src into dst. Mappings are merged by key and lists of scalars are combined,
+// other values of src replace the values of dst.
+func mergeNodes(path string, dst, src *yaml.Node, changes *[]Change) {
+	switch {
+	case dst.Kind == yaml.MappingNode && src.Kind == yaml.MappingNode:
+		for i := 0; i+1 < len(src.Content); i += 2 {
+			key, value := src.Content[i], src.Content[i+1]
+			keyPath := joinPath(path, key.Value)
+
+			existingValue := mappingValue(dst, key.Value)
+			if existingValue == nil {
+				dst.Content = append(dst.Content, key, value)
+				*changes = append(*changes, Change{Kind: Added, Path: keyPath, New: summary(value)})
+				continue
+			}
+			mergeNodes(keyPath, existingValue, value, changes)
+		}
+
+	case dst.Kind == yaml.SequenceNode && src.Kind == yaml.SequenceNode && scalarsOnly(dst) && scalarsOnly(src):
+		for _, item := range src.Content {
+			if !containsScalar(dst, item.Value) {
+				dst.Content = append(dst.Content, item)
+				*changes = append(*changes, Change{Kind: Added, Path: path + "[]", New: item.Value})
+			}
+		}
+
+	default:
+		if equalNodes(dst, src) {
+			return
+		}
+		*changes = append(*changes, Change{Kind: Changed, Path: path, Old: summary(dst), New: summary(src)})
+
+		// Comments of the existing value are kept if the new value has none
+		replacement := *src
+		if replacement.HeadComment == "" {
+			replacement.HeadComment = dst.HeadComment
+		}
+		if replacement.LineComment == "" {
+			replacement.LineComment = dst.LineComment
+		}
+		if replacement.FootComment == "" {
+			replacement.FootComment = dst.FootComment
+		}
+		*dst = replacement
+	}
+}
+
+// mappingValue returns the value of the key in the mapping node, nil if the key does not exist
+func mappingValue(mapping *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			return mapping.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// scalarsOnly returns true if all items of the sequence are scalars
+func scalarsOnly(sequence *yaml.Node) bool {
+	for _, item := range sequence.Content {
+		if item.Kind != yaml.ScalarNode {
+			return false
+		}
+	}
+	return true
+}
+
+// containsScalar returns true if the sequence contains a scalar with the value
+func containsScalar(sequence *yaml.Node, value string) bool {
+	for _, item := range sequence.Content {
+		if item.Value == value {
+			return true
+		}
+	}
+	return false
+}
+
+// equalNodes compares the values of two nodes, ignoring comments and styles
+func equalNodes(a, b *yaml.Node) bool {
+	if a.Kind != b.Kind || len(a.Content) != len(b.Content) {
+		return false
+	}
+	if a.Kind == yaml.ScalarNode {
+		return a.Value == b.Value && a.ShortTag() == b.ShortTag()
+	}
+	for i := range a.Content {
+		if !equalNodes(a.Content[i], b.Content[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// summary describes a value in a single line
+func summary(node *yaml.Node) string {
+	switch node.Kind {
+	case yaml.MappingNode:
+		return fmt.Sprintf("{%d keys}", len(node.Content)/2)
+	case yaml.SequenceNode:
+		return fmt.Sprintf("[%d items]", len(node.Content))
+	case yaml.AliasNode:
+		return "*" + node.Value
+	}
+	return node.Value
+}
+
+// joinPath appends a key to a dotted path, keys containing dots are quoted
+func joinPath(path, key string) string {
+	if strings.Contains(key, ".") {
+		key = fmt.Sprintf("%q", key)
+	}
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// detectIndent returns the indentation of the first indented line, or the default
+func detectIndent(content []byte, defaultIndent string) string {
+	for _, line := range strings.Split(string(content), "\n") {
+		trimmed := strings.TrimLeft(line, " \t")
+		if indent := line[:len(line)-len(trimmed)]; indent != "" && trimmed != "" && !strings.HasPrefix(trimmed, "- ") {
+			return indent
+		}
+	}
+	return defaultIndent
+}
+
+// jsonWriter writes YAML nodes parsed from JSON as JSON
+type jsonWriter struct {
+	buf    *bytes.Buffer
+	indent string
+}
+
+func (w jsonWriter) write(node *yaml.Node, level int) error {
+	switch node.Kind {
+	case yaml.MappingNode:
+		if len(node.Content) == 0 {
+			w.buf.WriteString("{}")
+			return nil
+		}
+		w.buf.WriteString("{\n")
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			w.buf.WriteString(strings.Repeat(w.indent, level+1))
+			w.writeString(node.Content[i].Value)
+			w.buf.WriteString(": ")
+			if err := w.write(node.Content[i+1], level+1); err != nil {
+				return err
+			}
+			if i+2 < len(node.Content) {
+				w.buf.WriteString(",")
+			}
+			w.buf.WriteString("\n")
+		}
+		w.buf.WriteString(strings.Repeat(w.indent, level) + "}")
+
+	case yaml.SequenceNode:
+		if len(node.Content) == 0 {
+			w.buf.WriteString("[]")
+			return nil
+		}
+		w.buf.WriteString("[\n")
+		for i, item := range node.Content {
+			w.buf.WriteString(strings.Repeat(w.indent, level+1))
+			if err := w.write(item, level+1); err != nil {
+				return err
+			}
+			if i+1 < len(node.Content) {
+				w.buf.WriteString(",")
+			}
+			w.buf.WriteString("\n")
+		}
+		w.buf.WriteString(strings.Repeat(w.indent, level) + "]")
+
+	case yaml.ScalarNode:
+		switch node.ShortTag() {
+		case "!!str":
+			w.writeString(node.Value)
+		case "!!null":
+			w.buf.WriteString("null")
+		default:
+			w.buf.WriteString(node.Value)
+		}
+
+	default:
+		return fmt.Errorf("unsupported JSON value at line %d", node.Line)
+	}
+	return nil
+}
+
+// writeString writes a JSON string without escaping HTML characters
+func (w jsonWriter) writeString(s string) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	_ = enc.Encode(s)
+	w.buf.Write(bytes.TrimSuffix(buf.Bytes(), []byte("\n")))
+}
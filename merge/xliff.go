@@ -0,0 +1,177 @@
+package merge
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+)
+
+// transUnit is a trans-unit element of an XLIFF file with its position in the file
+type transUnit struct {
+	id string
+	// raw is the element as written in the file
+	raw   []byte
+	start int
+	end   int
+}
+
+// xliffDocument are the trans-units of an XLIFF file and the position of the closing body tag
+type xliffDocument struct {
+	units   []transUnit
+	bodyEnd int
+}
+
+// mergeXLIFF merges the trans-units of the upstream XLIFF file into the existing file by id.
+// Changed units are replaced and new units are added at the end of the body, the rest of the file is kept as it is.
+func mergeXLIFF(existing, upstream []byte) ([]byte, []Change, error) {
+	if len(bytes.TrimSpace(existing)) == 0 {
+		return upstream, []Change{{Kind: Added, Path: ".", New: "all trans-units"}}, nil
+	}
+
+	existingDoc, err := parseXLIFF(existing)
+	if err != nil {
+		return nil, nil, fmt.Errorf("parsing existing file: %w", err)
+	}
+	upstreamDoc, err := parseXLIFF(upstream)
+	if err != nil {
+		return nil, nil, fmt.Errorf("parsing component file: %w", err)
+	}
+
+	type replacement struct {
+		start, end int
+		raw        []byte
+	}
+	var replacements []replacement
+	var added [][]byte
+	var changes []Change
+
+	for _, unit := range upstreamDoc.units {
+		idx := slices.IndexFunc(existingDoc.units, func(u transUnit) bool { return u.id == unit.id })
+		if idx < 0 {
+			added = append(added, unit.raw)
+			changes = append(changes, Change{Kind: Added, Path: unit.id, New: unitText(unit.raw)})
+			continue
+		}
+
+		existingUnit := existingDoc.units[idx]
+		if normalizeSpace(existingUnit.raw) == normalizeSpace(unit.raw) {
+			continue
+		}
+		replacements = append(replacements, replacement{start: existingUnit.start, end: existingUnit.end, raw: unit.raw})
+		changes = append(changes, Change{Kind: Changed, Path: unit.id, Old: unitText(existingUnit.raw), New: unitText(unit.raw)})
+	}
+
+	if len(changes) == 0 {
+		return existing, nil, nil
+	}
+
+	// New units are inserted on a new line before the closing body tag, indented like the last existing unit
+	if len(added) > 0 {
+		indent := "\t\t\t"
+		if len(existingDoc.units) > 0 {
+			indent = lineIndent(existing, existingDoc.units[len(existingDoc.units)-1].start)
+		}
+		insertAt := bytes.LastIndexByte(existing[:existingDoc.bodyEnd], '\n')
+		if insertAt < 0 {
+			insertAt = existingDoc.bodyEnd
+		}
+
+		var buf bytes.Buffer
+		for _, raw := range added {
+			buf.WriteString("\n" + indent)
+			buf.Write(raw)
+		}
+		replacements = append(replacements, replacement{start: insertAt, end: insertAt, raw: buf.Bytes()})
+	}
+
+	// Apply replacements from the end, so positions stay valid
+	slices.SortFunc(replacements, func(a, b replacement) int { return b.start - a.start })
+	merged := slices.Clone(existing)
+	for _, r := range replacements {
+		merged = slices.Concat(merged[:r.start], r.raw, merged[r.end:])
+	}
+
+	return merged, changes, nil
+}
+
+// parseXLIFF finds the trans-units and the end of the body of the first file in an XLIFF document
+func parseXLIFF(content []byte) (*xliffDocument, error) {
+	doc := &xliffDocument{bodyEnd: -1}
+	d := xml.NewDecoder(bytes.NewReader(content))
+	for {
+		offset := int(d.InputOffset())
+		tok, err := d.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			if t.Name.Local != "trans-unit" {
+				continue
+			}
+			if err := d.Skip(); err != nil {
+				return nil, err
+			}
+			end := int(d.InputOffset())
+			doc.units = append(doc.units, transUnit{
+				id:    attrValue(t, "id"),
+				raw:   content[offset:end],
+				start: offset,
+				end:   end,
+			})
+		case xml.EndElement:
+			if t.Name.Local == "body" && doc.bodyEnd < 0 {
+				doc.bodyEnd = offset
+			}
+		}
+	}
+
+	if doc.bodyEnd < 0 {
+		return nil, fmt.Errorf("no body element found")
+	}
+	return doc, nil
+}
+
+// attrValue returns the value of the attribute of the element
+func attrValue(element xml.StartElement, name string) string {
+	for _, attr := range element.Attr {
+		if attr.Name.Local == name {
+			return attr.Value
+		}
+	}
+	return ""
+}
+
+// unitText returns the text of a trans-unit in a single line
+func unitText(raw []byte) string {
+	var unit struct {
+		Source string `xml:"source"`
+		Target string `xml:"target"`
+	}
+	if err := xml.Unmarshal(raw, &unit); err != nil {
+		return normalizeSpace(raw)
+	}
+	if unit.Target != "" {
+		return unit.Target
+	}
+	return unit.Source
+}
+
+// normalizeSpace collapses all whitespace to single spaces
+func normalizeSpace(raw []byte) string {
+	return strings.Join(strings.Fields(string(raw)), " ")
+}
+
+// lineIndent returns the whitespace between the start of the line and the position
+func lineIndent(content []byte, pos int) string {
+	lineStart := bytes.LastIndexByte(content[:pos], '\n') + 1
+	return strings.TrimRight(string(content[lineStart:pos]), "\r")
+}
@@ -0,0 +1,203 @@
+package registry
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-git/go-billy/v5"
+	"github.com/go-git/go-billy/v5/memfs"
+)
+
+const (
+	// archiveFile is the name of the downloaded archive inside a cache entry
+	archiveFile = "archive"
+	// checksumFragmentPrefix is the URL fragment prefix to verify the SHA-256 checksum of an archive
+	checksumFragmentPrefix = "sha256="
+)
+
+// archiveFormat is the format of a registry archive
+type archiveFormat int
+
+const (
+	archiveFormatNone archiveFormat = iota
+	archiveFormatTarGz
+	archiveFormatZip
+)
+
+// archiveFormatOf detects the archive format of a registry location by its file extension.
+// Only http:// and https:// URLs can be archive registries.
+func archiveFormatOf(location string) archiveFormat {
+	u, err := url.Parse(location)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return archiveFormatNone
+	}
+
+	name := strings.ToLower(u.Path)
+	switch {
+	case strings.HasSuffix(name, ".tar.gz"), strings.HasSuffix(name, ".tgz"):
+		return archiveFormatTarGz
+	case strings.HasSuffix(name, ".zip"):
+		return archiveFormatZip
+	}
+	return archiveFormatNone
+}
+
+// isArchiveURL checks if the given location is an HTTP URL of a registry archive
+func isArchiveURL(location string) bool {
+	return archiveFormatOf(location) != archiveFormatNone
+}
+
+// getArchiveRegistry downloads (or revalidates) the archive and returns a registry with its extracted content.
+// A checksum can be given as URL fragment (e.g. https://example.com/registry.tar.gz#sha256=...).
+func (c *Cache) getArchiveRegistry(location string) (*Registry, error) {
+	u, err := url.Parse(location)
+	if err != nil {
+		return nil, fmt.Errorf("parsing registry URL: %w", err)
+	}
+
+	var checksum string
+	if u.Fragment != "" {
+		if !strings.HasPrefix(u.Fragment, checksumFragmentPrefix) {
+			return nil, fmt.Errorf("unsupported checksum %q, expected %s<hex>", u.Fragment, checksumFragmentPrefix)
+		}
+		checksum = strings.ToLower(strings.TrimPrefix(u.Fragment, checksumFragmentPrefix))
+		u.Fragment = ""
+	}
+
+	entry := c.entryFor(location)
+
+	lock, err := acquireLock(entry.lockPath())
+	if err != nil {
+		return nil, fmt.Errorf("locking cache entry: %w", err)
+	}
+	defer lock.Release()
+
+	if err := c.download(entry, u, entry.archivePath()); err != nil {
+		if _, statErr := os.Stat(entry.archivePath()); statErr != nil {
+			return nil, err
+		}
+		slog.Warn("Using cached registry archive, download failed", "url", u.Redacted(), "error", err)
+	}
+
+	data, err := os.ReadFile(entry.archivePath())
+	if err != nil {
+		return nil, fmt.Errorf("reading cached archive: %w", err)
+	}
+
+	if checksum != "" {
+		sum := sha256.Sum256(data)
+		if actual := hex.EncodeToString(sum[:]); actual != checksum {
+			return nil, fmt.Errorf("checksum mismatch for %s: expected %s, got %s", u.Redacted(), checksum, actual)
+		}
+	}
+
+	fs := memfs.New()
+	switch archiveFormatOf(location) {
+	case archiveFormatTarGz:
+		err = extractTarGz(data, fs)
+	case archiveFormatZip:
+		err = extractZip(data, fs)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("extracting archive: %w", err)
+	}
+
+	slog.Debug("Extracted registry archive to in-memory filesystem", "url", u.Redacted())
+
+	return newRegistry(location, nil, fs), nil
+}
+
+// archivePath returns the path of the downloaded archive of the entry
+func (e cacheEntry) archivePath() string {
+	return filepath.Join(e.path, archiveFile)
+}
+
+// extractTarGz extracts regular files of a gzipped tarball into the filesystem
+func extractTarGz(data []byte, fs billy.Filesystem) error {
+	gzipReader, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("opening gzip: %w", err)
+	}
+	defer gzipReader.Close()
+
+	tarReader := tar.NewReader(gzipReader)
+	for {
+		header, err := tarReader.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("reading tar: %w", err)
+		}
+
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		if err := writeArchiveFile(fs, header.Name, tarReader); err != nil {
+			return err
+		}
+	}
+}
+
+// extractZip extracts regular files of a zip archive into the filesystem
+func extractZip(data []byte, fs billy.Filesystem) error {
+	zipReader, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return fmt.Errorf("opening zip: %w", err)
+	}
+
+	for _, file := range zipReader.File {
+		if !file.Mode().IsRegular() {
+			continue
+		}
+
+		rc, err := file.Open()
+		if err != nil {
+			return fmt.Errorf("opening %s: %w", file.Name, err)
+		}
+		err = writeArchiveFile(fs, file.Name, rc)
+		rc.Close()
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// writeArchiveFile writes a file from an archive, rejecting paths outside of the archive root
+func writeArchiveFile(fs billy.Filesystem, name string, r io.Reader) error {
+	name = strings.ReplaceAll(name, "\\", "/")
+	for _, segment := range strings.Split(name, "/") {
+		if segment == ".." {
+			return fmt.Errorf("invalid path %s in archive", name)
+		}
+	}
+	name = strings.TrimPrefix(path.Clean("/"+name), "/")
+
+	file, err := fs.Create(name)
+	if err != nil {
+		return fmt.Errorf("creating %s: %w", name, err)
+	}
+	defer file.Close()
+
+	if _, err := io.Copy(file, r); err != nil {
+		return fmt.Errorf("writing %s: %w", name, err)
+	}
+
+	return nil
+}
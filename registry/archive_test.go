@@ -0,0 +1,103 @@
+package registry_test
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/networkteam/shry/config"
+	"github.com/networkteam/shry/registry"
+)
+
+func TestGetRegistryFromArchive(t *testing.T) {
+	archive := buildTarGz(t, map[string]string{
+		"registry-1.0.0/neos/button/shry.yaml":     "name: button\nplatform: neos\nfiles:\n  - src: Button.fusion\n    dst: Button.fusion\n",
+		"registry-1.0.0/neos/button/Button.fusion": "prototype(Button) < prototype(Neos.Fusion:Component)\n",
+	})
+	sum := sha256.Sum256(archive)
+	checksum := hex.EncodeToString(sum[:])
+
+	modified := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	var requests, notModified int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			notModified++
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		http.ServeContent(w, r, "registry.tar.gz", modified, bytes.NewReader(archive))
+	}))
+	defer server.Close()
+
+	cache, err := registry.NewCache(t.TempDir(), &config.GlobalConfig{DisableGitCredentials: true})
+	if err != nil {
+		t.Fatalf("NewCache() unexpected error: %v", err)
+	}
+
+	location := server.URL + "/registry.tar.gz#sha256=" + checksum
+	for range 2 {
+		reg, err := cache.GetRegistry(location, "", t.TempDir())
+		if err != nil {
+			t.Fatalf("GetRegistry() unexpected error: %v", err)
+		}
+
+		component, err := reg.ResolveComponent("neos", "button")
+		if err != nil {
+			t.Fatalf("ResolveComponent() unexpected error: %v", err)
+		}
+
+		content, err := reg.ReadFile(component.Path + "/Button.fusion")
+		if err != nil {
+			t.Fatalf("ReadFile() unexpected error: %v", err)
+		}
+		if string(content) != "prototype(Button) < prototype(Neos.Fusion:Component)\n" {
+			t.Errorf("ReadFile() = %q", content)
+		}
+	}
+
+	if requests != 2 || notModified != 1 {
+		t.Errorf("requests = %d, not modified = %d, want 2 requests with 1 revalidated", requests, notModified)
+	}
+
+	_, err = cache.GetRegistry(server.URL+"/registry.tar.gz#sha256=0000", "", t.TempDir())
+	if err == nil {
+		t.Error("GetRegistry() with wrong checksum expected error, got nil")
+	}
+}
+
+func buildTarGz(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	gzipWriter := gzip.NewWriter(&buf)
+	tarWriter := tar.NewWriter(gzipWriter)
+	for name, content := range files {
+		if err := tarWriter.WriteHeader(&tar.Header{
+			Name:     name,
+			Mode:     0644,
+			Size:     int64(len(content)),
+			Typeflag: tar.TypeReg,
+		}); err != nil {
+			t.Fatalf("writing tar header: %v", err)
+		}
+		if _, err := tarWriter.Write([]byte(content)); err != nil {
+			t.Fatalf("writing tar content: %v", err)
+		}
+	}
+	if err := tarWriter.Close(); err != nil {
+		t.Fatalf("closing tar: %v", err)
+	}
+	if err := gzipWriter.Close(); err != nil {
+		t.Fatalf("closing gzip: %v", err)
+	}
+
+	return buf.Bytes()
+}
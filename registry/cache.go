@@ -6,7 +6,6 @@ import (
 	"log/slog"
 	"os"
 	"path/filepath"
-	"strings"
 
 	"github.com/go-git/go-billy/v5/memfs"
 	"github.com/go-git/go-billy/v5/osfs"
@@ -14,12 +13,14 @@ import (
 	gitconfig "github.com/go-git/go-git/v5/config"
 	"github.com/go-git/go-git/v5/plumbing"
 	"github.com/go-git/go-git/v5/plumbing/protocol/packp/sideband"
+	"github.com/go-git/go-git/v5/plumbing/transport"
 	"github.com/go-git/go-git/v5/storage/memory"
+	"github.com/mitchellh/go-homedir"
 
 	"github.com/networkteam/shry/config"
 )
 
-// Cache manages a local cache of Git registry clones and local directories
+// Cache manages a local cache of Git registry clones, downloaded archives and local directories
 type Cache struct {
 	// Base directory for all registry clones
 	baseDir string
@@ -42,11 +43,14 @@ func (c *Cache) GetRegistry(location string, ref string, projectRoot string) (*R
 	// Check if this is a local path
 	if !isGitURL(location) {
 		// Resolve the path relative to the project root
-		absPath := location
+		absPath, err := homedir.Expand(location)
+		if err != nil {
+			return nil, fmt.Errorf("expanding registry path: %w", err)
+		}
 		if !filepath.IsAbs(absPath) {
 			absPath = filepath.Join(projectRoot, absPath)
 		}
-		absPath, err := filepath.Abs(absPath)
+		absPath, err = filepath.Abs(absPath)
 		if err != nil {
 			return nil, fmt.Errorf("resolving registry path: %w", err)
 		}
@@ -56,13 +60,37 @@ func (c *Cache) GetRegistry(location string, ref string, projectRoot string) (*R
 		return newRegistry(absPath, nil, fs), nil
 	}
 
+	// Handle HTTP archive
+	if isArchiveURL(location) {
+		return c.getArchiveRegistry(location)
+	}
+
+	// Handle static HTTP registry
+	if isStaticURL(location) {
+		return c.getStaticRegistry(location)
+	}
+
+	// Handle shadcn/ui registry
+	if isShadcnURL(location) {
+		return c.getShadcnRegistry(location)
+	}
+
 	// Handle Git repository
-	// Create a safe directory name from the URL
-	dirName := strings.ReplaceAll(location, "/", "_")
-	repoPath := filepath.Join(c.baseDir, dirName)
+	gitURL, err := GitURL(location)
+	if err != nil {
+		return nil, err
+	}
+
+	entry := c.entryFor(location)
+	repoPath := entry.path
+
+	endpoint, err := transport.NewEndpoint(gitURL)
+	if err != nil {
+		return nil, fmt.Errorf("parsing registry URL: %w", err)
+	}
 
 	// Get authentication method
-	auth, err := c.globalConfig.GetAuth(location)
+	auth, err := c.globalConfig.GetAuth(location, endpoint)
 	if err != nil {
 		return nil, fmt.Errorf("getting auth: %w", err)
 	}
@@ -72,6 +100,13 @@ func (c *Cache) GetRegistry(location string, ref string, projectRoot string) (*R
 		progress = os.Stderr
 	}
 
+	// Prevent concurrent shry processes from modifying the same repository
+	lock, err := acquireLock(entry.lockPath())
+	if err != nil {
+		return nil, fmt.Errorf("locking cache entry: %w", err)
+	}
+	defer lock.Release()
+
 	// Check if repository already exists
 	bareRepo, err := git.PlainOpen(repoPath)
 	if err == nil {
@@ -97,7 +132,7 @@ func (c *Cache) GetRegistry(location string, ref string, projectRoot string) (*R
 		}
 		// Clone the repository as bare
 		bareRepo, err = git.PlainClone(repoPath, true, &git.CloneOptions{
-			URL:      fmt.Sprintf("https://%s", location),
+			URL:      gitURL,
 			Progress: progress,
 			Auth:     auth,
 		})
@@ -108,6 +143,10 @@ func (c *Cache) GetRegistry(location string, ref string, projectRoot string) (*R
 		return nil, fmt.Errorf("failed to open repository: %w", err)
 	}
 
+	if err := entry.writeMetadata(cacheMetadata{}); err != nil {
+		return nil, err
+	}
+
 	// Resolve the reference from the bare repository
 	var referenceName plumbing.ReferenceName
 	if ref != "" {
@@ -135,18 +174,10 @@ func (c *Cache) GetRegistry(location string, ref string, projectRoot string) (*R
 
 	slog.Debug("Cloned cache repository to in-memory worktree", "url", location, "ref", ref, "referenceName", referenceName)
 
-	return newRegistry(ref, repo, fs), nil
+	return newRegistry(location, repo, fs), nil
 }
 
 // Clear removes all cached repositories
 func (c *Cache) Clear() error {
 	return os.RemoveAll(c.baseDir)
 }
-
-// isGitURL checks if the given URL is a Git URL
-func isGitURL(url string) bool {
-	if url == "" {
-		return false
-	}
-	return !(filepath.IsAbs(url) || filepath.VolumeName(url) != "" || url[0] == '.' || url[0] == '/')
-}
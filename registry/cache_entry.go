@@ -0,0 +1,92 @@
+package registry
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// cacheMetadataSuffix is appended to the entry path for the metadata file
+	cacheMetadataSuffix = ".meta.yaml"
+	// cacheLockSuffix is appended to the entry path for the lock file
+	cacheLockSuffix = ".lock"
+)
+
+// cacheEntry is a single registry inside the cache directory.
+// Entries are keyed by a hash of the registry location, so distinct locations never share a directory.
+type cacheEntry struct {
+	// Location of the registry as configured by the user
+	location string
+	// Path of the entry directory (e.g. the bare repository)
+	path string
+}
+
+// cacheMetadata is stored next to each cache entry to map it back to its location
+type cacheMetadata struct {
+	// Location of the registry
+	Location string `yaml:"location"`
+	// ETag of a downloaded archive
+	ETag string `yaml:"etag,omitempty"`
+	// Last-Modified header of a downloaded archive
+	LastModified string `yaml:"lastModified,omitempty"`
+}
+
+// entryFor returns the cache entry for the given registry location
+func (c *Cache) entryFor(location string) cacheEntry {
+	sum := sha256.Sum256([]byte(location))
+	return cacheEntry{
+		location: location,
+		path:     filepath.Join(c.baseDir, hex.EncodeToString(sum[:16])),
+	}
+}
+
+// lockPath returns the path of the lock file guarding the entry
+func (e cacheEntry) lockPath() string {
+	return e.path + cacheLockSuffix
+}
+
+// metadataPath returns the path of the metadata file of the entry
+func (e cacheEntry) metadataPath() string {
+	return e.path + cacheMetadataSuffix
+}
+
+// readMetadata reads the metadata file of the entry, a missing file results in empty metadata
+func (e cacheEntry) readMetadata() (cacheMetadata, error) {
+	var meta cacheMetadata
+
+	data, err := os.ReadFile(e.metadataPath())
+	if errors.Is(err, os.ErrNotExist) {
+		return meta, nil
+	}
+	if err != nil {
+		return meta, fmt.Errorf("reading cache metadata: %w", err)
+	}
+
+	if err := yaml.Unmarshal(data, &meta); err != nil {
+		return meta, fmt.Errorf("parsing cache metadata: %w", err)
+	}
+
+	return meta, nil
+}
+
+// writeMetadata writes the metadata file of the entry
+func (e cacheEntry) writeMetadata(meta cacheMetadata) error {
+	meta.Location = e.location
+
+	data, err := yaml.Marshal(meta)
+	if err != nil {
+		return fmt.Errorf("marshalling cache metadata: %w", err)
+	}
+
+	if err := os.WriteFile(e.metadataPath(), data, 0644); err != nil {
+		return fmt.Errorf("writing cache metadata: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,150 @@
+package registry_test
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"gopkg.in/yaml.v3"
+
+	"github.com/networkteam/shry/config"
+	"github.com/networkteam/shry/registry"
+)
+
+func TestGetGitRegistry(t *testing.T) {
+	repoDir := newGitRepository(t, map[string]string{
+		"neos/button/shry.yaml":     "name: button\nplatform: neos\nfiles:\n  - src: Button.fusion\n    dst: Button.fusion\n",
+		"neos/button/Button.fusion": "prototype(Button) < prototype(Neos.Fusion:Component)\n",
+	})
+
+	cache, err := registry.NewCache(t.TempDir(), &config.GlobalConfig{DisableGitCredentials: true})
+	if err != nil {
+		t.Fatalf("NewCache() unexpected error: %v", err)
+	}
+
+	location := "file://" + filepath.ToSlash(repoDir)
+	for _, ref := range []string{"", "main"} {
+		reg, err := cache.GetRegistry(location, ref, t.TempDir())
+		if err != nil {
+			t.Fatalf("GetRegistry() with ref %q unexpected error: %v", ref, err)
+		}
+		if !reg.IsGit() {
+			t.Errorf("IsGit() = false, want true")
+		}
+		// The name is the location, not the ref
+		if reg.Name != location {
+			t.Errorf("Name = %q, want %q", reg.Name, location)
+		}
+		if _, err := reg.ResolveComponent("neos", "button"); err != nil {
+			t.Errorf("ResolveComponent() unexpected error: %v", err)
+		}
+	}
+}
+
+func TestGitRegistryCacheEntries(t *testing.T) {
+	files := map[string]string{
+		"neos/button/shry.yaml": "name: button\nplatform: neos\nfiles: []\n",
+	}
+	// Both locations mapped to the same directory when slashes were replaced by underscores
+	baseDir := t.TempDir()
+	locations := []string{
+		"file://" + filepath.ToSlash(filepath.Join(baseDir, "a", "b_c")),
+		"file://" + filepath.ToSlash(filepath.Join(baseDir, "a_b", "c")),
+	}
+	for _, location := range locations {
+		newGitRepositoryAt(t, filepath.FromSlash(strings.TrimPrefix(location, "file://")), files)
+	}
+
+	cacheDir := t.TempDir()
+	cache, err := registry.NewCache(cacheDir, &config.GlobalConfig{DisableGitCredentials: true})
+	if err != nil {
+		t.Fatalf("NewCache() unexpected error: %v", err)
+	}
+	for _, location := range locations {
+		// Refs of a location are resolved from the same clone
+		for _, ref := range []string{"", "main"} {
+			if _, err := cache.GetRegistry(location, ref, t.TempDir()); err != nil {
+				t.Fatalf("GetRegistry(%s, %q) unexpected error: %v", location, ref, err)
+			}
+		}
+	}
+
+	metadataFiles, err := filepath.Glob(filepath.Join(cacheDir, "*.meta.yaml"))
+	if err != nil {
+		t.Fatalf("Glob() unexpected error: %v", err)
+	}
+	var cachedLocations []string
+	for _, metadataFile := range metadataFiles {
+		data, err := os.ReadFile(metadataFile)
+		if err != nil {
+			t.Fatalf("reading %s: %v", metadataFile, err)
+		}
+		var metadata struct {
+			Location string `yaml:"location"`
+		}
+		if err := yaml.Unmarshal(data, &metadata); err != nil {
+			t.Fatalf("parsing %s: %v", metadataFile, err)
+		}
+		cachedLocations = append(cachedLocations, metadata.Location)
+
+		// The metadata file belongs to the cloned repository of the entry
+		if _, err := git.PlainOpen(strings.TrimSuffix(metadataFile, ".meta.yaml")); err != nil {
+			t.Errorf("opening cached repository of %s: %v", metadata.Location, err)
+		}
+	}
+
+	slices.Sort(cachedLocations)
+	if !slices.Equal(cachedLocations, locations) {
+		t.Errorf("cached locations = %v, want one entry for each of %v", cachedLocations, locations)
+	}
+}
+
+// newGitRepository creates a Git repository on the main branch with the files committed
+func newGitRepository(t *testing.T, files map[string]string) string {
+	t.Helper()
+
+	return newGitRepositoryAt(t, t.TempDir(), files)
+}
+
+// newGitRepositoryAt creates a Git repository in dir on the main branch with the files committed
+func newGitRepositoryAt(t *testing.T, dir string, files map[string]string) string {
+	t.Helper()
+
+	repo, err := git.PlainInitWithOptions(dir, &git.PlainInitOptions{
+		InitOptions: git.InitOptions{DefaultBranch: "refs/heads/main"},
+	})
+	if err != nil {
+		t.Fatalf("PlainInit() unexpected error: %v", err)
+	}
+	worktree, err := repo.Worktree()
+	if err != nil {
+		t.Fatalf("Worktree() unexpected error: %v", err)
+	}
+
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("creating directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("writing %s: %v", name, err)
+		}
+		if _, err := worktree.Add(name); err != nil {
+			t.Fatalf("Add() unexpected error: %v", err)
+		}
+	}
+
+	_, err = worktree.Commit("Add components", &git.CommitOptions{
+		Author: &object.Signature{Name: "Test", Email: "test@example.com", When: time.Now()},
+	})
+	if err != nil {
+		t.Fatalf("Commit() unexpected error: %v", err)
+	}
+
+	return dir
+}
@@ -0,0 +1,161 @@
+package registry
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	githttp "github.com/go-git/go-git/v5/plumbing/transport/http"
+)
+
+const (
+	// httpResponseHeaderTimeout limits the time to wait for a registry server to respond
+	httpResponseHeaderTimeout = 30 * time.Second
+	// httpTimeout limits the total time of a download including the body
+	httpTimeout = 10 * time.Minute
+)
+
+// httpClient is used for all downloads from HTTP registries.
+// The timeouts ensure a stalled server cannot block shry while the cache entry is locked.
+var httpClient = newHTTPClient()
+
+func newHTTPClient() *http.Client {
+	httpTransport := http.DefaultTransport.(*http.Transport).Clone()
+	httpTransport.ResponseHeaderTimeout = httpResponseHeaderTimeout
+
+	return &http.Client{
+		Transport: httpTransport,
+		Timeout:   httpTimeout,
+	}
+}
+
+// newHTTPRequest creates a GET request with the authentication configured for the registry location
+func (c *Cache) newHTTPRequest(location string, u *url.URL) (*http.Request, error) {
+	req, err := http.NewRequest(http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("creating request: %w", err)
+	}
+
+	endpoint, err := transport.NewEndpoint(u.String())
+	if err != nil {
+		return nil, fmt.Errorf("parsing registry URL: %w", err)
+	}
+	auth, err := c.globalConfig.GetAuth(location, endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("getting auth: %w", err)
+	}
+	if httpAuth, ok := auth.(githttp.AuthMethod); ok {
+		httpAuth.SetAuth(req)
+	}
+
+	return req, nil
+}
+
+// fetch downloads the URL without caching
+func (c *Cache) fetch(location string, u *url.URL) ([]byte, error) {
+	req, err := c.newHTTPRequest(location, u)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("downloading %s: %w", u.Redacted(), err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, statusError(u, resp)
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("downloading %s: %w", u.Redacted(), err)
+	}
+
+	return data, nil
+}
+
+// download downloads the URL to a file of the cache entry.
+// A cached file is revalidated with ETag and Last-Modified headers.
+func (c *Cache) download(entry cacheEntry, u *url.URL, path string) error {
+	meta, err := entry.readMetadata()
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(path); err != nil {
+		// Without a cached file, validators must not be sent
+		meta = cacheMetadata{}
+	}
+
+	req, err := c.newHTTPRequest(entry.location, u)
+	if err != nil {
+		return err
+	}
+	if meta.ETag != "" {
+		req.Header.Set("If-None-Match", meta.ETag)
+	}
+	if meta.LastModified != "" {
+		req.Header.Set("If-Modified-Since", meta.LastModified)
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("downloading %s: %w", u.Redacted(), err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusNotModified:
+		slog.Debug("Cached download not modified", "url", u.Redacted())
+		return nil
+	case http.StatusOK:
+	default:
+		return statusError(u, resp)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("creating cache entry: %w", err)
+	}
+
+	// Write to a temporary file first, so an interrupted download never replaces a complete file
+	tmpFile, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return fmt.Errorf("creating temporary file: %w", err)
+	}
+	defer os.Remove(tmpFile.Name())
+
+	if c.Verbose {
+		fmt.Fprintf(os.Stderr, "Downloading %s\n", u.Redacted())
+	}
+
+	if _, err := io.Copy(tmpFile, resp.Body); err != nil {
+		tmpFile.Close()
+		return fmt.Errorf("downloading %s: %w", u.Redacted(), err)
+	}
+	if err := tmpFile.Close(); err != nil {
+		return fmt.Errorf("writing download: %w", err)
+	}
+	if err := os.Rename(tmpFile.Name(), path); err != nil {
+		return fmt.Errorf("writing download: %w", err)
+	}
+
+	return entry.writeMetadata(cacheMetadata{
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+	})
+}
+
+// statusError returns an error for an unexpected HTTP response status
+func statusError(u *url.URL, resp *http.Response) error {
+	if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden {
+		return fmt.Errorf("downloading %s: %s: %w", u.Redacted(), resp.Status, transport.ErrAuthenticationRequired)
+	}
+	return fmt.Errorf("downloading %s: unexpected status %s", u.Redacted(), resp.Status)
+}
@@ -0,0 +1,38 @@
+package registry
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// fileLock is an advisory lock on a file shared between shry processes
+type fileLock struct {
+	file *os.File
+}
+
+// acquireLock blocks until an exclusive advisory lock on the given path is held.
+// The lock file is created if it doesn't exist.
+func acquireLock(path string) (*fileLock, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("creating lock directory: %w", err)
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("opening lock file: %w", err)
+	}
+
+	if err := lockFile(file); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("locking %s: %w", path, err)
+	}
+
+	return &fileLock{file: file}, nil
+}
+
+// Release releases the lock
+func (l *fileLock) Release() error {
+	defer l.file.Close()
+	return unlockFile(l.file)
+}
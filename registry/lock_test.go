@@ -0,0 +1,49 @@
+package registry
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestAcquireLockExcludesSecondHolder(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "entry", "registry.lock")
+
+	first, err := acquireLock(path)
+	if err != nil {
+		t.Fatalf("acquireLock() unexpected error: %v", err)
+	}
+
+	acquired := make(chan *fileLock)
+	go func() {
+		second, err := acquireLock(path)
+		if err != nil {
+			t.Errorf("acquireLock() of second holder unexpected error: %v", err)
+			close(acquired)
+			return
+		}
+		acquired <- second
+	}()
+
+	select {
+	case <-acquired:
+		t.Fatal("second holder acquired the lock while the first holds it")
+	case <-time.After(200 * time.Millisecond):
+	}
+
+	if err := first.Release(); err != nil {
+		t.Fatalf("Release() unexpected error: %v", err)
+	}
+
+	select {
+	case second := <-acquired:
+		if second == nil {
+			return
+		}
+		if err := second.Release(); err != nil {
+			t.Errorf("Release() of second holder unexpected error: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("second holder did not acquire the lock after it was released")
+	}
+}
@@ -1,8 +1,10 @@
 package registry
 
 import (
+	"errors"
 	"fmt"
 	"io"
+	"os"
 
 	"github.com/go-git/go-billy/v5"
 	"github.com/go-git/go-git/v5"
@@ -10,19 +12,30 @@ import (
 	"github.com/networkteam/shry/config"
 )
 
-// Registry represents a component registry that can be either a Git repository or a local directory
+// Registry represents a component registry that can be either a Git repository, a local directory or a remote HTTP registry
 type Registry struct {
-	Name string
-	repo *git.Repository // nil for local directories
-	fs   billy.Filesystem
+	// Name is the location of the registry, local directories use their absolute path
+	Name   string
+	repo   *git.Repository // nil for local directories
+	source source
 }
 
-// newRegistry creates a new Registry instance
+// source provides the components and files of a registry
+type source interface {
+	// ScanComponents returns all components by platform and name
+	ScanComponents() (map[string]map[string]*config.Component, error)
+	// ReadFile reads a file by its path in the registry
+	ReadFile(path string) ([]byte, error)
+	// Manifest returns the registry manifest, nil if the registry has none
+	Manifest() (*config.RegistryManifest, error)
+}
+
+// newRegistry creates a new Registry instance backed by a filesystem
 func newRegistry(name string, repo *git.Repository, fs billy.Filesystem) *Registry {
 	return &Registry{
-		Name: name,
-		repo: repo,
-		fs:   fs,
+		Name:   name,
+		repo:   repo,
+		source: fsSource{fs: fs},
 	}
 }
 
@@ -33,7 +46,66 @@ func (r *Registry) IsGit() bool {
 
 // ReadFile reads a file from the registry
 func (r *Registry) ReadFile(path string) ([]byte, error) {
-	file, err := r.fs.Open(path)
+	return r.source.ReadFile(path)
+}
+
+// Manifest returns the manifest of the registry, nil if the registry has none
+func (r *Registry) Manifest() (*config.RegistryManifest, error) {
+	return r.source.Manifest()
+}
+
+// ResolvePlatform returns the platform name for a name or alias defined in the registry manifest
+func (r *Registry) ResolvePlatform(platform string) (string, error) {
+	manifest, err := r.Manifest()
+	if err != nil {
+		return "", err
+	}
+	if manifest == nil {
+		return platform, nil
+	}
+	return manifest.ResolvePlatform(platform), nil
+}
+
+// ScanComponents scans the registry for components.
+// Components with a platform alias of the registry manifest are returned for the platform.
+func (r *Registry) ScanComponents() (map[string]map[string]*config.Component, error) {
+	components, err := r.source.ScanComponents()
+	if err != nil {
+		return nil, err
+	}
+	manifest, err := r.Manifest()
+	if err != nil {
+		return nil, err
+	}
+	if manifest == nil {
+		return components, nil
+	}
+
+	resolved := make(map[string]map[string]*config.Component, len(components))
+	for platform, platformComponents := range components {
+		platform = manifest.ResolvePlatform(platform)
+		if _, exists := resolved[platform]; !exists {
+			resolved[platform] = make(map[string]*config.Component, len(platformComponents))
+		}
+		for name, component := range platformComponents {
+			if _, exists := resolved[platform][name]; exists {
+				return nil, fmt.Errorf("duplicate component %s found in platform %s and its aliases", name, platform)
+			}
+			component.Platform = platform
+			resolved[platform][name] = component
+		}
+	}
+	return resolved, nil
+}
+
+// fsSource reads a registry from a filesystem (Git worktree, local directory or extracted archive)
+type fsSource struct {
+	fs billy.Filesystem
+}
+
+// ReadFile reads a file from the filesystem
+func (s fsSource) ReadFile(path string) ([]byte, error) {
+	file, err := s.fs.Open(path)
 	if err != nil {
 		return nil, fmt.Errorf("opening file: %w", err)
 	}
@@ -42,9 +114,21 @@ func (r *Registry) ReadFile(path string) ([]byte, error) {
 	return io.ReadAll(file)
 }
 
-// ScanComponents scans the registry for components
-func (r *Registry) ScanComponents() (map[string]map[string]*config.Component, error) {
-	return config.ScanComponents(r.fs, ".")
+// ScanComponents scans the filesystem for component configurations
+func (s fsSource) ScanComponents() (map[string]map[string]*config.Component, error) {
+	return config.ScanComponents(s.fs, ".")
+}
+
+// Manifest reads the manifest at the root of the filesystem
+func (s fsSource) Manifest() (*config.RegistryManifest, error) {
+	data, err := s.ReadFile(config.RegistryManifestFile)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading registry manifest: %w", err)
+	}
+	return config.ParseRegistryManifest(data)
 }
 
 // ResolveComponent resolves a component by name for the given platform and verifies its variables
@@ -55,7 +139,11 @@ func (r *Registry) ResolveComponent(platform, name string) (*config.Component, e
 		return nil, fmt.Errorf("scanning components: %w", err)
 	}
 
-	// Lookup platform components
+	// Lookup platform components, the platform can be an alias
+	platform, err = r.ResolvePlatform(platform)
+	if err != nil {
+		return nil, err
+	}
 	platformComponents, exists := components[platform]
 	if !exists {
 		return nil, fmt.Errorf("no components found for platform %s", platform)
@@ -69,3 +157,75 @@ func (r *Registry) ResolveComponent(platform, name string) (*config.Component, e
 
 	return component, nil
 }
+
+// ResolveComponents resolves the components by name for the given platform including their dependencies.
+// Dependencies are ordered before the components depending on them, every component is returned only once.
+func (r *Registry) ResolveComponents(platform string, names []string) ([]*config.Component, error) {
+	return r.ResolveComponentsWithVariants(platform, names, nil)
+}
+
+// ResolveComponentsWithVariants resolves the components like ResolveComponents and applies the variants by component name.
+// Variants of dependencies are applied as well, their overrides can add dependencies.
+func (r *Registry) ResolveComponentsWithVariants(platform string, names []string, variants map[string]string) ([]*config.Component, error) {
+	// Scan components
+	components, err := r.ScanComponents()
+	if err != nil {
+		return nil, fmt.Errorf("scanning components: %w", err)
+	}
+
+	// Lookup platform components, the platform can be an alias
+	platform, err = r.ResolvePlatform(platform)
+	if err != nil {
+		return nil, err
+	}
+	platformComponents, exists := components[platform]
+	if !exists {
+		return nil, fmt.Errorf("no components found for platform %s", platform)
+	}
+
+	var resolved []*config.Component
+	visited := make(map[string]bool)
+	inProgress := make(map[string]bool)
+
+	var visit func(name string, dependent string) error
+	visit = func(name string, dependent string) error {
+		if visited[name] {
+			return nil
+		}
+		if inProgress[name] {
+			return fmt.Errorf("circular dependency between %s and %s", dependent, name)
+		}
+
+		component, exists := platformComponents[name]
+		if !exists {
+			if dependent != "" {
+				return fmt.Errorf("dependency %s of component %s not found for platform %s", name, dependent, platform)
+			}
+			return fmt.Errorf("component %s not found for platform %s", name, platform)
+		}
+		component, err := component.WithVariant(variants[name])
+		if err != nil {
+			return err
+		}
+
+		inProgress[name] = true
+		for _, dependency := range component.Dependencies {
+			if err := visit(dependency, name); err != nil {
+				return err
+			}
+		}
+		delete(inProgress, name)
+
+		visited[name] = true
+		resolved = append(resolved, component)
+		return nil
+	}
+
+	for _, name := range names {
+		if err := visit(name, ""); err != nil {
+			return nil, err
+		}
+	}
+
+	return resolved, nil
+}
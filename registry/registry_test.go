@@ -0,0 +1,239 @@
+package registry_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/networkteam/shry/config"
+	"github.com/networkteam/shry/registry"
+)
+
+func TestResolveComponents(t *testing.T) {
+	registryDir := t.TempDir()
+	writeFiles(t, registryDir, map[string]string{
+		"neos/button/shry.yaml": "name: button\nplatform: neos\nfiles: []\n",
+		"neos/icon/shry.yaml":   "name: icon\nplatform: neos\nfiles: []\n",
+		"neos/card/shry.yaml":   "name: card\nplatform: neos\ndependencies: [button, icon]\nfiles: []\n",
+		"neos/teaser/shry.yaml": "name: teaser\nplatform: neos\ndependencies: [card, button]\nfiles: []\n",
+		"neos/broken/shry.yaml": "name: broken\nplatform: neos\ndependencies: [missing]\nfiles: []\n",
+		"neos/loop-a/shry.yaml": "name: loop-a\nplatform: neos\ndependencies: [loop-b]\nfiles: []\n",
+		"neos/loop-b/shry.yaml": "name: loop-b\nplatform: neos\ndependencies: [loop-a]\nfiles: []\n",
+	})
+
+	cache, err := registry.NewCache(t.TempDir(), &config.GlobalConfig{})
+	if err != nil {
+		t.Fatalf("NewCache() unexpected error: %v", err)
+	}
+	reg, err := cache.GetRegistry(registryDir, "", t.TempDir())
+	if err != nil {
+		t.Fatalf("GetRegistry() unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name        string
+		names       []string
+		expected    []string
+		expectedErr bool
+	}{
+		{
+			name:     "dependencies first",
+			names:    []string{"teaser"},
+			expected: []string{"button", "icon", "card", "teaser"},
+		},
+		{
+			name:     "selection without duplicates",
+			names:    []string{"card", "button", "teaser"},
+			expected: []string{"button", "icon", "card", "teaser"},
+		},
+		{
+			name:        "missing dependency",
+			names:       []string{"broken"},
+			expectedErr: true,
+		},
+		{
+			name:        "circular dependency",
+			names:       []string{"loop-a"},
+			expectedErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			components, err := reg.ResolveComponents("neos", tt.names)
+			if tt.expectedErr {
+				if err == nil {
+					t.Error("ResolveComponents() expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ResolveComponents() unexpected error: %v", err)
+			}
+
+			var actual []string
+			for _, component := range components {
+				actual = append(actual, component.Name)
+			}
+			if !reflect.DeepEqual(actual, tt.expected) {
+				t.Errorf("ResolveComponents() = %v, want %v", actual, tt.expected)
+			}
+		})
+	}
+}
+
+func TestResolveComponentsWithVariants(t *testing.T) {
+	registryDir := t.TempDir()
+	writeFiles(t, registryDir, map[string]string{
+		"neos/icon/shry.yaml": "name: icon\nplatform: neos\nfiles: []\n",
+		"neos/button/shry.yaml": `name: button
+platform: neos
+variables:
+  style: solid
+files:
+  - src: Button.fusion
+    dst: Button.fusion
+  - src: Button.yaml
+    dst: Button.yaml
+variants:
+  outline:
+    variables:
+      style: outline
+  icon:
+    dependencies: [icon]
+    files:
+      - src: variants/icon/Button.fusion
+        dst: Button.fusion
+      - src: Icon.fusion
+        dst: Icon.fusion
+`,
+	})
+
+	cache, err := registry.NewCache(t.TempDir(), &config.GlobalConfig{})
+	if err != nil {
+		t.Fatalf("NewCache() unexpected error: %v", err)
+	}
+	reg, err := cache.GetRegistry(registryDir, "", t.TempDir())
+	if err != nil {
+		t.Fatalf("GetRegistry() unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name          string
+		variant       string
+		expected      []string
+		expectedFiles []string
+		expectedStyle any
+		expectedErr   bool
+	}{
+		{
+			name:          "base component",
+			expected:      []string{"button"},
+			expectedFiles: []string{"Button.fusion", "Button.yaml"},
+			expectedStyle: "solid",
+		},
+		{
+			name:          "variant overrides variables",
+			variant:       "outline",
+			expected:      []string{"button"},
+			expectedFiles: []string{"Button.fusion", "Button.yaml"},
+			expectedStyle: "outline",
+		},
+		{
+			name:          "variant overrides files and adds dependencies",
+			variant:       "icon",
+			expected:      []string{"icon", "button"},
+			expectedFiles: []string{"variants/icon/Button.fusion", "Button.yaml", "Icon.fusion"},
+			expectedStyle: "solid",
+		},
+		{
+			name:        "unknown variant",
+			variant:     "ghost",
+			expectedErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			components, err := reg.ResolveComponentsWithVariants("neos", []string{"button"}, map[string]string{"button": tt.variant})
+			if tt.expectedErr {
+				if err == nil {
+					t.Error("ResolveComponentsWithVariants() expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ResolveComponentsWithVariants() unexpected error: %v", err)
+			}
+
+			var actual []string
+			for _, component := range components {
+				actual = append(actual, component.Name)
+			}
+			if !reflect.DeepEqual(actual, tt.expected) {
+				t.Errorf("ResolveComponentsWithVariants() = %v, want %v", actual, tt.expected)
+			}
+
+			button := components[len(components)-1]
+			if button.Variant != tt.variant {
+				t.Errorf("Variant = %q, want %q", button.Variant, tt.variant)
+			}
+			var files []string
+			for _, file := range button.Files {
+				files = append(files, file.Src)
+			}
+			if !reflect.DeepEqual(files, tt.expectedFiles) {
+				t.Errorf("Files = %v, want %v", files, tt.expectedFiles)
+			}
+			if style := button.ResolveVariables(nil)["style"]; style != tt.expectedStyle {
+				t.Errorf("style = %v, want %v", style, tt.expectedStyle)
+			}
+		})
+	}
+}
+
+func TestScanComponentsWithPlatformAliases(t *testing.T) {
+	registryDir := t.TempDir()
+	writeFiles(t, registryDir, map[string]string{
+		"shry-registry.yaml":      "name: Acme\nplatforms:\n  neos:\n    aliases: [neos-cms]\n",
+		"neos/button/shry.yaml":   "name: button\nplatform: neos\nfiles: []\n",
+		"neos-cms/card/shry.yaml": "name: card\nplatform: neos-cms\ndependencies: [button]\nfiles: []\n",
+		"react/button/shry.yaml":  "name: button\nplatform: react\nfiles: []\n",
+	})
+
+	cache, err := registry.NewCache(t.TempDir(), &config.GlobalConfig{})
+	if err != nil {
+		t.Fatalf("NewCache() unexpected error: %v", err)
+	}
+	reg, err := cache.GetRegistry(registryDir, "", t.TempDir())
+	if err != nil {
+		t.Fatalf("GetRegistry() unexpected error: %v", err)
+	}
+
+	manifest, err := reg.Manifest()
+	if err != nil {
+		t.Fatalf("Manifest() unexpected error: %v", err)
+	}
+	if manifest == nil || manifest.Name != "Acme" {
+		t.Errorf("Manifest() = %v, want name %q", manifest, "Acme")
+	}
+
+	components, err := reg.ScanComponents()
+	if err != nil {
+		t.Fatalf("ScanComponents() unexpected error: %v", err)
+	}
+	if len(components["neos"]) != 2 || len(components["react"]) != 1 || len(components) != 2 {
+		t.Errorf("ScanComponents() = %v, want neos with 2 and react with 1 component", components)
+	}
+	if platform := components["neos"]["card"].Platform; platform != "neos" {
+		t.Errorf("Platform of card = %q, want %q", platform, "neos")
+	}
+
+	// Projects can use the alias
+	resolved, err := reg.ResolveComponents("neos-cms", []string{"card"})
+	if err != nil {
+		t.Fatalf("ResolveComponents() unexpected error: %v", err)
+	}
+	if len(resolved) != 2 {
+		t.Errorf("ResolveComponents() returned %d components, want 2", len(resolved))
+	}
+}
@@ -0,0 +1,285 @@
+package registry
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/networkteam/shry/config"
+)
+
+//go:embed catalog.html.tmpl
+var catalogTemplateSource string
+
+var catalogTemplate = template.Must(template.New("catalog").Funcs(template.FuncMap{
+	"fileURL":    componentFileURL,
+	"previewURL": previewURL,
+}).Parse(catalogTemplateSource))
+
+// ServerOptions configures the registry server
+type ServerOptions struct {
+	// Title shown in the catalog
+	Title string
+	// Static enables serving the static registry protocol below /r/
+	Static bool
+}
+
+// NewServer returns an HTTP handler serving an HTML catalog and a JSON API of the registry.
+// Components are scanned on every request, so changes to a local registry are visible without a restart.
+//
+// Routes:
+//   - GET / HTML catalog
+//   - GET /api/components list of components
+//   - GET /api/components/{platform}/{name} a single component
+//   - GET /api/components/{platform}/{name}/files/{src...} content of a component file
+//   - GET /r/index.json and /r/{platform}/{name}.json static registry protocol (if enabled)
+func NewServer(reg *Registry, opts ServerOptions) http.Handler {
+	s := &server{reg: reg, opts: opts}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /{$}", s.handleCatalog)
+	mux.HandleFunc("GET /api/components", s.handleListComponents)
+	mux.HandleFunc("GET /api/components/{platform}/{name}", s.handleGetComponent)
+	mux.HandleFunc("GET /api/components/{platform}/{name}/files/{src...}", s.handleGetFile)
+	if opts.Static {
+		mux.HandleFunc("GET /r/"+StaticIndexFile, s.handleStaticIndex)
+		mux.HandleFunc("GET /r/{platform}/{file}", s.handleStaticComponentFiles)
+	}
+
+	return mux
+}
+
+type server struct {
+	reg  *Registry
+	opts ServerOptions
+}
+
+// catalogPlatform is a platform with its components grouped by category for the catalog
+type catalogPlatform struct {
+	Name        string
+	Description string
+	Categories  []catalogCategory
+}
+
+// catalogCategory is a category with its components sorted by name
+type catalogCategory struct {
+	Name       string
+	Components []*config.Component
+}
+
+func (s *server) handleCatalog(w http.ResponseWriter, r *http.Request) {
+	components, err := s.reg.ScanComponents()
+	if err != nil {
+		s.error(w, fmt.Errorf("scanning components: %w", err))
+		return
+	}
+	manifest, err := s.reg.Manifest()
+	if err != nil {
+		s.error(w, err)
+		return
+	}
+	if manifest == nil {
+		manifest = &config.RegistryManifest{}
+	}
+
+	// Group components by platform and category
+	var platforms []catalogPlatform
+	for _, platformName := range sortedKeys(components) {
+		byCategory := make(map[string][]*config.Component)
+		for _, component := range components[platformName] {
+			byCategory[component.Category] = append(byCategory[component.Category], component)
+		}
+
+		platform := catalogPlatform{Name: platformName, Description: manifest.Platforms[platformName].Description}
+		for _, categoryName := range sortedKeys(byCategory) {
+			categoryComponents := byCategory[categoryName]
+			sort.Slice(categoryComponents, func(i, j int) bool {
+				return categoryComponents[i].Name < categoryComponents[j].Name
+			})
+			platform.Categories = append(platform.Categories, catalogCategory{
+				Name:       categoryName,
+				Components: categoryComponents,
+			})
+		}
+		platforms = append(platforms, platform)
+	}
+
+	// The title of the server options takes precedence over the name of the registry
+	title := s.opts.Title
+	if title == "" {
+		title = manifest.Name
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := catalogTemplate.Execute(w, map[string]any{
+		"Title":       title,
+		"Description": manifest.Description,
+		"Static":      s.opts.Static,
+		"Platforms":   platforms,
+	}); err != nil {
+		slog.Warn("Rendering catalog failed", "error", err)
+	}
+}
+
+func (s *server) handleListComponents(w http.ResponseWriter, r *http.Request) {
+	components, err := s.reg.ScanComponents()
+	if err != nil {
+		s.error(w, fmt.Errorf("scanning components: %w", err))
+		return
+	}
+
+	list := make([]*config.Component, 0)
+	for _, platform := range sortedKeys(components) {
+		for _, name := range sortedKeys(components[platform]) {
+			list = append(list, components[platform][name])
+		}
+	}
+
+	writeJSONResponse(w, list)
+}
+
+func (s *server) handleGetComponent(w http.ResponseWriter, r *http.Request) {
+	component, ok := s.resolveComponent(w, r.PathValue("platform"), r.PathValue("name"))
+	if !ok {
+		return
+	}
+
+	writeJSONResponse(w, component)
+}
+
+func (s *server) handleGetFile(w http.ResponseWriter, r *http.Request) {
+	component, ok := s.resolveComponent(w, r.PathValue("platform"), r.PathValue("name"))
+	if !ok {
+		return
+	}
+
+	// Only files inside the component directory can be read
+	src := path.Clean("/" + r.PathValue("src"))[1:]
+	content, err := s.reg.ReadFile(filepath.Join(component.Path, filepath.FromSlash(src)))
+	if err != nil {
+		http.Error(w, fmt.Sprintf("file %s not found", src), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	if contentType := contentTypeByExtension(src); contentType != "" {
+		w.Header().Set("Content-Type", contentType)
+	}
+	w.Write(content)
+}
+
+func (s *server) handleStaticIndex(w http.ResponseWriter, r *http.Request) {
+	components, err := s.reg.ScanComponents()
+	if err != nil {
+		s.error(w, fmt.Errorf("scanning components: %w", err))
+		return
+	}
+
+	manifest, err := s.reg.Manifest()
+	if err != nil {
+		s.error(w, err)
+		return
+	}
+
+	writeJSONResponse(w, StaticIndex{
+		Version:   StaticFormatVersion,
+		Platforms: components,
+		Manifest:  manifest,
+	})
+}
+
+func (s *server) handleStaticComponentFiles(w http.ResponseWriter, r *http.Request) {
+	name, ok := strings.CutSuffix(r.PathValue("file"), ".json")
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	component, ok := s.resolveComponent(w, r.PathValue("platform"), name)
+	if !ok {
+		return
+	}
+
+	componentFiles, err := ReadStaticComponentFiles(s.reg, component)
+	if err != nil {
+		s.error(w, err)
+		return
+	}
+
+	writeJSONResponse(w, componentFiles)
+}
+
+// resolveComponent resolves the component or responds with not found
+func (s *server) resolveComponent(w http.ResponseWriter, platform, name string) (*config.Component, bool) {
+	component, err := s.reg.ResolveComponent(platform, name)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return nil, false
+	}
+	return component, true
+}
+
+// error logs the error and responds with an internal server error
+func (s *server) error(w http.ResponseWriter, err error) {
+	slog.Warn("Serving registry failed", "error", err)
+	http.Error(w, err.Error(), http.StatusInternalServerError)
+}
+
+// writeJSONResponse writes the value as JSON response
+func writeJSONResponse(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(v); err != nil {
+		slog.Warn("Encoding JSON response failed", "error", err)
+	}
+}
+
+// componentFileURL returns the API URL of a component file
+func componentFileURL(component *config.Component, src string) string {
+	return "/api/components/" + url.PathEscape(component.Platform) + "/" + url.PathEscape(component.Name) + "/files/" + (&url.URL{Path: filepath.ToSlash(src)}).EscapedPath()
+}
+
+// previewURL returns the URL of a preview image, relative images are served from the component directory
+func previewURL(component *config.Component) string {
+	image := component.Preview.Image
+	if u, err := url.Parse(image); err == nil && u.IsAbs() {
+		return image
+	}
+	return componentFileURL(component, image)
+}
+
+// contentTypeByExtension returns the content type of image files, other files are served as text
+func contentTypeByExtension(name string) string {
+	switch strings.ToLower(path.Ext(name)) {
+	case ".png":
+		return "image/png"
+	case ".jpg", ".jpeg":
+		return "image/jpeg"
+	case ".gif":
+		return "image/gif"
+	case ".webp":
+		return "image/webp"
+	case ".svg":
+		return "image/svg+xml"
+	}
+	return ""
+}
+
+// sortedKeys returns the keys of the map in ascending order
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
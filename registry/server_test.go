@@ -0,0 +1,115 @@
+package registry_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/networkteam/shry/config"
+	"github.com/networkteam/shry/registry"
+)
+
+func TestServer(t *testing.T) {
+	registryDir := t.TempDir()
+	writeFiles(t, registryDir, map[string]string{
+		"neos/button/shry.yaml":     "name: button\ntitle: Button\nplatform: neos\ncategory: Atoms\nfiles:\n  - src: Button.fusion\n    dst: Button.fusion\n",
+		"neos/button/Button.fusion": "prototype(Button) < prototype(Neos.Fusion:Component)\n",
+	})
+
+	cache, err := registry.NewCache(t.TempDir(), &config.GlobalConfig{DisableGitCredentials: true})
+	if err != nil {
+		t.Fatalf("NewCache() unexpected error: %v", err)
+	}
+	reg, err := cache.GetRegistry(registryDir, "", t.TempDir())
+	if err != nil {
+		t.Fatalf("GetRegistry() unexpected error: %v", err)
+	}
+
+	server := httptest.NewServer(registry.NewServer(reg, registry.ServerOptions{Static: true}))
+	defer server.Close()
+
+	tests := []struct {
+		name           string
+		path           string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "catalog",
+			path:           "/",
+			expectedStatus: http.StatusOK,
+			expectedBody:   "<h4>Button</h4>",
+		},
+		{
+			name:           "list components",
+			path:           "/api/components",
+			expectedStatus: http.StatusOK,
+			expectedBody:   `"name": "button"`,
+		},
+		{
+			name:           "get file",
+			path:           "/api/components/neos/button/files/Button.fusion",
+			expectedStatus: http.StatusOK,
+			expectedBody:   "prototype(Button)",
+		},
+		{
+			name:           "unknown component",
+			path:           "/api/components/neos/card",
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   "component card not found",
+		},
+		{
+			name:           "static index",
+			path:           "/r/index.json",
+			expectedStatus: http.StatusOK,
+			expectedBody:   `"version": 1`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := http.Get(server.URL + tt.path)
+			if err != nil {
+				t.Fatalf("GET %s unexpected error: %v", tt.path, err)
+			}
+			defer resp.Body.Close()
+			body, _ := io.ReadAll(resp.Body)
+
+			if resp.StatusCode != tt.expectedStatus {
+				t.Errorf("GET %s status = %d, want %d", tt.path, resp.StatusCode, tt.expectedStatus)
+			}
+			if !strings.Contains(string(body), tt.expectedBody) {
+				t.Errorf("GET %s body does not contain %q:\n%s", tt.path, tt.expectedBody, body)
+			}
+		})
+	}
+
+	// Another shry client can install from the served static registry
+	client, err := cache.GetRegistry(server.URL+"/r/index.json", "", t.TempDir())
+	if err != nil {
+		t.Fatalf("GetRegistry() unexpected error: %v", err)
+	}
+	content, err := client.ReadFile("neos/button/Button.fusion")
+	if err != nil {
+		t.Fatalf("ReadFile() unexpected error: %v", err)
+	}
+	if string(content) != "prototype(Button) < prototype(Neos.Fusion:Component)\n" {
+		t.Errorf("ReadFile() = %q", content)
+	}
+
+	resp, err := http.Get(server.URL + "/api/components")
+	if err != nil {
+		t.Fatalf("GET /api/components unexpected error: %v", err)
+	}
+	defer resp.Body.Close()
+	var components []config.Component
+	if err := json.NewDecoder(resp.Body).Decode(&components); err != nil {
+		t.Fatalf("decoding components: %v", err)
+	}
+	if len(components) != 1 || components[0].Category != "Atoms" {
+		t.Errorf("GET /api/components = %+v", components)
+	}
+}
@@ -0,0 +1,273 @@
+package registry
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/networkteam/shry/config"
+)
+
+const (
+	// shadcnIndexFile is the name of the index of a shadcn registry
+	shadcnIndexFile = "registry.json"
+	// shadcnPlatformFragmentPrefix is the URL fragment prefix to choose the platform of a shadcn registry
+	shadcnPlatformFragmentPrefix = "platform="
+	// shadcnDefaultPlatform is the platform of a shadcn registry if none is chosen
+	shadcnDefaultPlatform = "react"
+)
+
+// shadcnRegistry is the registry.json of a shadcn/ui registry
+type shadcnRegistry struct {
+	Name  string       `json:"name"`
+	Items []shadcnItem `json:"items"`
+}
+
+// shadcnItem is a registry item of a shadcn/ui registry
+type shadcnItem struct {
+	Name                 string       `json:"name"`
+	Type                 string       `json:"type"`
+	Title                string       `json:"title"`
+	Description          string       `json:"description"`
+	Categories           []string     `json:"categories"`
+	RegistryDependencies []string     `json:"registryDependencies"`
+	Dependencies         []string     `json:"dependencies"`
+	DevDependencies      []string     `json:"devDependencies"`
+	Files                []shadcnFile `json:"files"`
+}
+
+// shadcnFile is a file of a shadcn/ui registry item, the content is only included in the item JSON
+type shadcnFile struct {
+	Path    string  `json:"path"`
+	Type    string  `json:"type"`
+	Target  string  `json:"target"`
+	Content *string `json:"content"`
+}
+
+// isShadcnURL checks if the given location is an HTTP URL of a shadcn registry.json
+func isShadcnURL(location string) bool {
+	u, err := url.Parse(location)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return false
+	}
+	return path.Base(u.Path) == shadcnIndexFile
+}
+
+// getShadcnRegistry downloads (or revalidates) a shadcn registry.json and maps its items to components.
+// The platform can be chosen with a URL fragment (e.g. https://example.com/r/registry.json#platform=next).
+// Items are fetched on demand from <name>.json next to the registry.json.
+func (c *Cache) getShadcnRegistry(location string) (*Registry, error) {
+	u, err := url.Parse(location)
+	if err != nil {
+		return nil, fmt.Errorf("parsing registry URL: %w", err)
+	}
+
+	platform := shadcnDefaultPlatform
+	if u.Fragment != "" {
+		if !strings.HasPrefix(u.Fragment, shadcnPlatformFragmentPrefix) {
+			return nil, fmt.Errorf("unsupported option %q, expected %s<name>", u.Fragment, shadcnPlatformFragmentPrefix)
+		}
+		platform = strings.TrimPrefix(u.Fragment, shadcnPlatformFragmentPrefix)
+		u.Fragment = ""
+	}
+
+	entry := c.entryFor(location)
+	indexPath := filepath.Join(entry.path, shadcnIndexFile)
+
+	lock, err := acquireLock(entry.lockPath())
+	if err != nil {
+		return nil, fmt.Errorf("locking cache entry: %w", err)
+	}
+	defer lock.Release()
+
+	if err := c.download(entry, u, indexPath); err != nil {
+		if _, statErr := os.Stat(indexPath); statErr != nil {
+			return nil, err
+		}
+		slog.Warn("Using cached registry index, download failed", "url", u.Redacted(), "error", err)
+	}
+
+	data, err := os.ReadFile(indexPath)
+	if err != nil {
+		return nil, fmt.Errorf("reading cached index: %w", err)
+	}
+
+	var index shadcnRegistry
+	if err := json.Unmarshal(data, &index); err != nil {
+		return nil, fmt.Errorf("parsing shadcn registry: %w", err)
+	}
+
+	components := make(map[string]*config.Component, len(index.Items))
+	for _, item := range index.Items {
+		component := item.toComponent(platform)
+		components[component.Name] = component
+	}
+
+	return &Registry{
+		Name: location,
+		source: &shadcnSource{
+			cache:      c,
+			location:   location,
+			indexURL:   u,
+			name:       index.Name,
+			platform:   platform,
+			components: components,
+			items:      make(map[string]*shadcnItem),
+		},
+	}, nil
+}
+
+// toComponent maps the item to a component of the given platform
+func (item shadcnItem) toComponent(platform string) *config.Component {
+	component := &config.Component{
+		Path:        path.Join(platform, item.Name),
+		Name:        item.Name,
+		Title:       item.Title,
+		Description: item.Description,
+		Platform:    platform,
+	}
+	if len(item.Categories) > 0 {
+		component.Category = item.Categories[0]
+	}
+
+	for _, file := range item.Files {
+		component.Files = append(component.Files, config.File{
+			Src: file.Path,
+			Dst: file.destination(),
+		})
+	}
+
+	for _, dependency := range item.RegistryDependencies {
+		// Dependencies on other registries (URLs or namespaces) cannot be resolved in this registry
+		if strings.Contains(dependency, "/") || strings.HasPrefix(dependency, "@") {
+			slog.Debug("Skipping external registry dependency", "component", item.Name, "dependency", dependency)
+			continue
+		}
+		component.Dependencies = append(component.Dependencies, dependency)
+	}
+
+	// npm dependencies are given as name or name@version (e.g. clsx or @radix-ui/react-slot@^1.0)
+	for _, dependency := range slices.Concat(item.Dependencies, item.DevDependencies) {
+		name, version := splitNPMDependency(dependency)
+		if component.Packages.NPM == nil {
+			component.Packages.NPM = make(map[string]string)
+		}
+		component.Packages.NPM[name] = version
+	}
+
+	return component
+}
+
+// splitNPMDependency splits an npm dependency into name and version, the version is empty if none is given
+func splitNPMDependency(dependency string) (name string, version string) {
+	if i := strings.LastIndex(dependency, "@"); i > 0 {
+		return dependency[:i], dependency[i+1:]
+	}
+	return dependency, ""
+}
+
+// destination returns the destination path of the file in a project.
+// Without an explicit target, files are placed like the shadcn CLI does with the default aliases.
+func (f shadcnFile) destination() string {
+	if f.Target != "" {
+		return strings.TrimPrefix(f.Target, "~/")
+	}
+
+	name := path.Base(f.Path)
+	switch f.Type {
+	case "registry:ui":
+		return path.Join("components", "ui", name)
+	case "registry:lib":
+		return path.Join("lib", name)
+	case "registry:hook":
+		return path.Join("hooks", name)
+	default:
+		return path.Join("components", name)
+	}
+}
+
+// shadcnSource reads components from a shadcn registry and fetches item files on demand
+type shadcnSource struct {
+	cache      *Cache
+	location   string
+	indexURL   *url.URL
+	name       string
+	platform   string
+	components map[string]*config.Component
+
+	mx    sync.Mutex
+	items map[string]*shadcnItem
+}
+
+// ScanComponents returns the items of the registry as components of the chosen platform
+func (s *shadcnSource) ScanComponents() (map[string]map[string]*config.Component, error) {
+	return map[string]map[string]*config.Component{
+		s.platform: s.components,
+	}, nil
+}
+
+// Manifest returns a manifest with the name of the shadcn registry
+func (s *shadcnSource) Manifest() (*config.RegistryManifest, error) {
+	if s.name == "" {
+		return nil, nil
+	}
+	return &config.RegistryManifest{Name: s.name}, nil
+}
+
+// ReadFile reads a file by its path <platform>/<name>/<path>, fetching the registry item if needed
+func (s *shadcnSource) ReadFile(filePath string) ([]byte, error) {
+	parts := strings.SplitN(filepath.ToSlash(filePath), "/", 3)
+	if len(parts) != 3 || parts[0] != s.platform {
+		return nil, fmt.Errorf("file %s not found", filePath)
+	}
+	name, src := parts[1], parts[2]
+
+	item, err := s.item(name)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, file := range item.Files {
+		if path.Clean(file.Path) == src && file.Content != nil {
+			return []byte(*file.Content), nil
+		}
+	}
+
+	return nil, fmt.Errorf("file %s not found", filePath)
+}
+
+// item fetches and memoizes a registry item with its file contents
+func (s *shadcnSource) item(name string) (*shadcnItem, error) {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+
+	if item, exists := s.items[name]; exists {
+		return item, nil
+	}
+
+	if _, exists := s.components[name]; !exists {
+		return nil, fmt.Errorf("component %s not found for platform %s", name, s.platform)
+	}
+
+	itemURL := s.indexURL.ResolveReference(&url.URL{Path: name + ".json"})
+	data, err := s.cache.fetch(s.location, itemURL)
+	if err != nil {
+		return nil, err
+	}
+
+	var item shadcnItem
+	if err := json.Unmarshal(data, &item); err != nil {
+		return nil, fmt.Errorf("parsing registry item %s: %w", name, err)
+	}
+
+	s.items[name] = &item
+
+	return &item, nil
+}
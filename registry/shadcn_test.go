@@ -0,0 +1,94 @@
+package registry_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/networkteam/shry/config"
+	"github.com/networkteam/shry/registry"
+)
+
+func TestGetRegistryFromShadcn(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/r/registry.json", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{
+  "name": "acme",
+  "items": [
+    {
+      "name": "login-form",
+      "type": "registry:block",
+      "title": "Login Form",
+      "categories": ["authentication"],
+      "registryDependencies": ["button", "https://example.com/r/input.json"],
+      "dependencies": ["clsx", "@radix-ui/react-slot@^1.0.2"],
+      "devDependencies": ["@types/react"],
+      "files": [
+        {"path": "registry/default/login-form/login-form.tsx", "type": "registry:component"},
+        {"path": "registry/default/login-form/use-login.ts", "type": "registry:hook"},
+        {"path": "registry/default/login-form/page.tsx", "type": "registry:page", "target": "app/login/page.tsx"}
+      ]
+    },
+    {
+      "name": "button",
+      "type": "registry:ui",
+      "files": [{"path": "registry/default/ui/button.tsx", "type": "registry:ui"}]
+    }
+  ]
+}`))
+	})
+	mux.HandleFunc("/r/login-form.json", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{
+  "name": "login-form",
+  "files": [
+    {"path": "registry/default/login-form/login-form.tsx", "type": "registry:component", "content": "export function LoginForm() {}\n"}
+  ]
+}`))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	cache, err := registry.NewCache(t.TempDir(), &config.GlobalConfig{DisableGitCredentials: true})
+	if err != nil {
+		t.Fatalf("NewCache() unexpected error: %v", err)
+	}
+
+	reg, err := cache.GetRegistry(server.URL+"/r/registry.json#platform=next", "", t.TempDir())
+	if err != nil {
+		t.Fatalf("GetRegistry() unexpected error: %v", err)
+	}
+
+	component, err := reg.ResolveComponent("next", "login-form")
+	if err != nil {
+		t.Fatalf("ResolveComponent() unexpected error: %v", err)
+	}
+
+	if component.Title != "Login Form" || component.Category != "authentication" {
+		t.Errorf("ResolveComponent() title = %q, category = %q", component.Title, component.Category)
+	}
+	if !reflect.DeepEqual(component.Dependencies, []string{"button"}) {
+		t.Errorf("ResolveComponent() dependencies = %v, want [button]", component.Dependencies)
+	}
+	expectedNPM := map[string]string{"clsx": "", "@radix-ui/react-slot": "^1.0.2", "@types/react": ""}
+	if !reflect.DeepEqual(component.Packages.NPM, expectedNPM) {
+		t.Errorf("ResolveComponent() npm packages = %v, want %v", component.Packages.NPM, expectedNPM)
+	}
+	expectedFiles := []config.File{
+		{Src: "registry/default/login-form/login-form.tsx", Dst: "components/login-form.tsx"},
+		{Src: "registry/default/login-form/use-login.ts", Dst: "hooks/use-login.ts"},
+		{Src: "registry/default/login-form/page.tsx", Dst: "app/login/page.tsx"},
+	}
+	if !reflect.DeepEqual(component.Files, expectedFiles) {
+		t.Errorf("ResolveComponent() files = %v, want %v", component.Files, expectedFiles)
+	}
+
+	content, err := reg.ReadFile(filepath.Join(component.Path, component.Files[0].Src))
+	if err != nil {
+		t.Fatalf("ReadFile() unexpected error: %v", err)
+	}
+	if string(content) != "export function LoginForm() {}\n" {
+		t.Errorf("ReadFile() = %q", content)
+	}
+}
@@ -0,0 +1,261 @@
+package registry
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/networkteam/shry/config"
+)
+
+const (
+	// StaticIndexFile is the name of the index of a static registry
+	StaticIndexFile = "index.json"
+	// StaticFormatVersion is the version of the static registry format
+	StaticFormatVersion = 1
+)
+
+// StaticIndex is the index of a static registry served over HTTP.
+// The files of each component are served as StaticComponentFiles at <platform>/<name>.json relative to the index.
+type StaticIndex struct {
+	// Version of the static registry format
+	Version int `json:"version"`
+	// Platforms with their components by name
+	Platforms map[string]map[string]*config.Component `json:"platforms"`
+	// Manifest of the registry, if it has one
+	Manifest *config.RegistryManifest `json:"manifest,omitempty"`
+}
+
+// StaticComponentFiles is the payload with the files of a component in a static registry
+type StaticComponentFiles struct {
+	// Files by their source path relative to the component directory
+	Files map[string][]byte `json:"files"`
+}
+
+// StaticComponentFilesPath returns the path of the component files payload relative to the index
+func StaticComponentFilesPath(platform, name string) string {
+	return platform + "/" + name + ".json"
+}
+
+// isStaticURL checks if the given location is an HTTP URL of a static registry index
+func isStaticURL(location string) bool {
+	u, err := url.Parse(location)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return false
+	}
+	return path.Base(u.Path) == StaticIndexFile
+}
+
+// getStaticRegistry downloads (or revalidates) the index of a static registry.
+// Component files are fetched on demand.
+func (c *Cache) getStaticRegistry(location string) (*Registry, error) {
+	indexURL, err := url.Parse(location)
+	if err != nil {
+		return nil, fmt.Errorf("parsing registry URL: %w", err)
+	}
+
+	entry := c.entryFor(location)
+	indexPath := filepath.Join(entry.path, StaticIndexFile)
+
+	lock, err := acquireLock(entry.lockPath())
+	if err != nil {
+		return nil, fmt.Errorf("locking cache entry: %w", err)
+	}
+	defer lock.Release()
+
+	if err := c.download(entry, indexURL, indexPath); err != nil {
+		if _, statErr := os.Stat(indexPath); statErr != nil {
+			return nil, err
+		}
+		slog.Warn("Using cached registry index, download failed", "url", indexURL.Redacted(), "error", err)
+	}
+
+	data, err := os.ReadFile(indexPath)
+	if err != nil {
+		return nil, fmt.Errorf("reading cached index: %w", err)
+	}
+
+	var index StaticIndex
+	if err := json.Unmarshal(data, &index); err != nil {
+		return nil, fmt.Errorf("parsing registry index: %w", err)
+	}
+	if index.Version != StaticFormatVersion {
+		return nil, fmt.Errorf("unsupported static registry version %d", index.Version)
+	}
+
+	// Components get a virtual path, so files can be mapped to their payload
+	for platform, components := range index.Platforms {
+		for name, component := range components {
+			component.Path = path.Join(platform, name)
+		}
+	}
+
+	return &Registry{
+		Name: location,
+		source: &staticSource{
+			cache:    c,
+			location: location,
+			indexURL: indexURL,
+			index:    &index,
+			files:    make(map[string]*StaticComponentFiles),
+		},
+	}, nil
+}
+
+// staticSource reads a registry from a static HTTP index and fetches component files on demand
+type staticSource struct {
+	cache    *Cache
+	location string
+	indexURL *url.URL
+	index    *StaticIndex
+
+	mx    sync.Mutex
+	files map[string]*StaticComponentFiles
+}
+
+// ScanComponents returns the components of the index
+func (s *staticSource) ScanComponents() (map[string]map[string]*config.Component, error) {
+	return s.index.Platforms, nil
+}
+
+// Manifest returns the manifest of the index
+func (s *staticSource) Manifest() (*config.RegistryManifest, error) {
+	return s.index.Manifest, nil
+}
+
+// ReadFile reads a file by its path <platform>/<name>/<src>, fetching the component files if needed
+func (s *staticSource) ReadFile(filePath string) ([]byte, error) {
+	parts := strings.SplitN(filepath.ToSlash(filePath), "/", 3)
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("file %s not found", filePath)
+	}
+	platform, name, src := parts[0], parts[1], parts[2]
+
+	componentFiles, err := s.componentFiles(platform, name)
+	if err != nil {
+		return nil, err
+	}
+
+	content, exists := componentFiles.Files[src]
+	if !exists {
+		return nil, fmt.Errorf("file %s not found", filePath)
+	}
+
+	return content, nil
+}
+
+// componentFiles fetches and memoizes the files payload of a component
+func (s *staticSource) componentFiles(platform, name string) (*StaticComponentFiles, error) {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+
+	key := path.Join(platform, name)
+	if files, exists := s.files[key]; exists {
+		return files, nil
+	}
+
+	if _, exists := s.index.Platforms[platform][name]; !exists {
+		return nil, fmt.Errorf("component %s not found for platform %s", name, platform)
+	}
+
+	filesURL := s.indexURL.ResolveReference(&url.URL{Path: StaticComponentFilesPath(platform, name)})
+	data, err := s.cache.fetch(s.location, filesURL)
+	if err != nil {
+		return nil, err
+	}
+
+	var files StaticComponentFiles
+	if err := json.Unmarshal(data, &files); err != nil {
+		return nil, fmt.Errorf("parsing files of component %s: %w", name, err)
+	}
+
+	s.files[key] = &files
+
+	return &files, nil
+}
+
+// BuildStatic writes the registry as a static registry to the output directory.
+// The output directory can be served by any HTTP server, the registry location is the URL of its index.json.
+func BuildStatic(reg *Registry, outputDir string) (*StaticIndex, error) {
+	components, err := reg.ScanComponents()
+	if err != nil {
+		return nil, fmt.Errorf("scanning components: %w", err)
+	}
+	manifest, err := reg.Manifest()
+	if err != nil {
+		return nil, err
+	}
+
+	index := &StaticIndex{
+		Version:   StaticFormatVersion,
+		Platforms: components,
+		Manifest:  manifest,
+	}
+
+	for platform, platformComponents := range components {
+		for name, component := range platformComponents {
+			componentFiles, err := ReadStaticComponentFiles(reg, component)
+			if err != nil {
+				return nil, err
+			}
+
+			if err := writeJSON(filepath.Join(outputDir, filepath.FromSlash(StaticComponentFilesPath(platform, name))), componentFiles); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if err := writeJSON(filepath.Join(outputDir, StaticIndexFile), index); err != nil {
+		return nil, err
+	}
+
+	return index, nil
+}
+
+// ReadStaticComponentFiles reads the files declared by the component and all of its variants into a static registry payload
+func ReadStaticComponentFiles(reg *Registry, component *config.Component) (*StaticComponentFiles, error) {
+	files := slices.Clone(component.Files)
+	for _, name := range component.VariantNames() {
+		files = append(files, component.Variants[name].Files...)
+	}
+
+	componentFiles := &StaticComponentFiles{Files: make(map[string][]byte)}
+	for _, file := range files {
+		src := filepath.ToSlash(filepath.Clean(file.Src))
+		if _, exists := componentFiles.Files[src]; exists {
+			continue
+		}
+		content, err := reg.ReadFile(filepath.Join(component.Path, file.Src))
+		if err != nil {
+			return nil, fmt.Errorf("reading file %s of component %s: %w", file.Src, component.Name, err)
+		}
+		componentFiles.Files[src] = content
+	}
+
+	return componentFiles, nil
+}
+
+// writeJSON writes the value as indented JSON, creating parent directories as needed
+func writeJSON(filePath string, v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshalling %s: %w", filePath, err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+		return fmt.Errorf("creating directory for %s: %w", filePath, err)
+	}
+
+	if err := os.WriteFile(filePath, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("writing %s: %w", filePath, err)
+	}
+
+	return nil
+}
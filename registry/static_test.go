@@ -0,0 +1,112 @@
+package registry_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/networkteam/shry/config"
+	"github.com/networkteam/shry/registry"
+)
+
+func TestBuildAndGetStaticRegistry(t *testing.T) {
+	registryDir := t.TempDir()
+	writeFiles(t, registryDir, map[string]string{
+		"neos/button/shry.yaml": "name: button\nplatform: neos\nfiles:\n  - src: Button.fusion\n    dst: Button.fusion\n" +
+			"variants:\n  icon:\n    files:\n      - src: variants/icon/Button.fusion\n        dst: Button.fusion\n",
+		"neos/button/Button.fusion":               "prototype(Button) < prototype(Neos.Fusion:Component)\n",
+		"neos/button/variants/icon/Button.fusion": "prototype(Button) < prototype(Acme:IconComponent)\n",
+		"neos/card/shry.yaml":                     "name: card\nplatform: neos\nfiles:\n  - src: Card.fusion\n    dst: Card.fusion\n",
+		"neos/card/Card.fusion":                   "prototype(Card) < prototype(Neos.Fusion:Component)\n",
+	})
+
+	cache, err := registry.NewCache(t.TempDir(), &config.GlobalConfig{DisableGitCredentials: true})
+	if err != nil {
+		t.Fatalf("NewCache() unexpected error: %v", err)
+	}
+
+	localReg, err := cache.GetRegistry(registryDir, "", t.TempDir())
+	if err != nil {
+		t.Fatalf("GetRegistry() unexpected error: %v", err)
+	}
+
+	outputDir := t.TempDir()
+	if _, err := registry.BuildStatic(localReg, outputDir); err != nil {
+		t.Fatalf("BuildStatic() unexpected error: %v", err)
+	}
+
+	var mx sync.Mutex
+	var requested []string
+	fileServer := http.FileServer(http.Dir(outputDir))
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mx.Lock()
+		requested = append(requested, r.URL.Path)
+		mx.Unlock()
+		fileServer.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+
+	reg, err := cache.GetRegistry(server.URL+"/"+registry.StaticIndexFile, "", t.TempDir())
+	if err != nil {
+		t.Fatalf("GetRegistry() unexpected error: %v", err)
+	}
+
+	components, err := reg.ScanComponents()
+	if err != nil {
+		t.Fatalf("ScanComponents() unexpected error: %v", err)
+	}
+	if len(components["neos"]) != 2 {
+		t.Errorf("ScanComponents() returned %d components, want 2", len(components["neos"]))
+	}
+
+	component, err := reg.ResolveComponent("neos", "button")
+	if err != nil {
+		t.Fatalf("ResolveComponent() unexpected error: %v", err)
+	}
+
+	content, err := reg.ReadFile(filepath.Join(component.Path, "Button.fusion"))
+	if err != nil {
+		t.Fatalf("ReadFile() unexpected error: %v", err)
+	}
+	if string(content) != "prototype(Button) < prototype(Neos.Fusion:Component)\n" {
+		t.Errorf("ReadFile() = %q", content)
+	}
+
+	// Files that only exist in a variant are part of the static registry
+	variantComponents, err := reg.ResolveComponentsWithVariants("neos", []string{"button"}, map[string]string{"button": "icon"})
+	if err != nil {
+		t.Fatalf("ResolveComponentsWithVariants() unexpected error: %v", err)
+	}
+	variant := variantComponents[0]
+	content, err = reg.ReadFile(filepath.Join(variant.Path, variant.Files[0].Src))
+	if err != nil {
+		t.Fatalf("ReadFile() of variant file unexpected error: %v", err)
+	}
+	if string(content) != "prototype(Button) < prototype(Acme:IconComponent)\n" {
+		t.Errorf("ReadFile() of variant file = %q", content)
+	}
+
+	// Only the index and the files of the used component must be fetched
+	expected := "/index.json,/neos/button.json"
+	if actual := strings.Join(requested, ","); actual != expected {
+		t.Errorf("requested = %s, want %s", actual, expected)
+	}
+}
+
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+
+	for name, content := range files {
+		filePath := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+			t.Fatalf("creating directory: %v", err)
+		}
+		if err := os.WriteFile(filePath, []byte(content), 0644); err != nil {
+			t.Fatalf("writing %s: %v", name, err)
+		}
+	}
+}
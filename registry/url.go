@@ -0,0 +1,145 @@
+package registry
+
+import (
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"strings"
+)
+
+// supportedSchemes lists the URL schemes that can be used for Git registries
+var supportedSchemes = map[string]bool{
+	"https": true,
+	"http":  true,
+	"ssh":   true,
+	"git":   true,
+	"file":  true,
+}
+
+// GitURL returns the URL to clone a Git registry location from.
+//
+// The following forms are supported:
+//   - host/path (e.g. github.com/networkteam/neos-components), cloned via HTTPS
+//   - URLs with an explicit scheme (https://, http://, ssh://, git://, file://), used as is
+//   - scp-style SSH locations (e.g. git@gitlab.company:team/registry.git), mapped to ssh://
+func GitURL(location string) (string, error) {
+	if scheme, _, ok := strings.Cut(location, "://"); ok {
+		if !supportedSchemes[scheme] {
+			return "", fmt.Errorf("unsupported registry URL scheme %q", scheme)
+		}
+		u, err := url.Parse(location)
+		if err != nil {
+			return "", fmt.Errorf("parsing registry URL: %w", err)
+		}
+		if u.Scheme != "file" && u.Host == "" {
+			return "", fmt.Errorf("registry URL %s has no host", location)
+		}
+		if u.Scheme == "file" && u.Path == "" {
+			return "", fmt.Errorf("registry URL %s has no path", location)
+		}
+		return location, nil
+	}
+
+	if user, host, path, ok := parseSCPLike(location); ok {
+		u := url.URL{
+			Scheme: "ssh",
+			Host:   host,
+			Path:   "/" + strings.TrimPrefix(path, "/"),
+		}
+		if user != "" {
+			u.User = url.User(user)
+		}
+		return u.String(), nil
+	}
+
+	return "https://" + location, nil
+}
+
+// IsSSH checks if the given registry location is a Git repository accessed via SSH
+func IsSSH(location string) bool {
+	if !isGitURL(location) {
+		return false
+	}
+	gitURL, err := GitURL(location)
+	return err == nil && strings.HasPrefix(gitURL, "ssh://")
+}
+
+// SplitRef splits an optional reference suffix (location@ref) from a registry specification.
+// An "@" that is part of the user info of an SSH location is not treated as a reference separator.
+// Local paths are returned unchanged, they can contain "@" (e.g. ../packages/@acme/registry).
+func SplitRef(spec string) (location string, ref string) {
+	if spec == "" || isLocalPath(spec) {
+		return spec, ""
+	}
+
+	// Determine where the path of the location starts, a reference can only follow in the path
+	pathStart := 0
+	if _, rest, ok := strings.Cut(spec, "://"); ok {
+		pathStart = len(spec) - len(rest)
+		if i := strings.Index(rest, "/"); i >= 0 {
+			pathStart += i
+		} else {
+			pathStart = len(spec)
+		}
+	} else if _, _, path, ok := parseSCPLike(spec); ok {
+		pathStart = len(spec) - len(path)
+	}
+
+	i := strings.LastIndex(spec[pathStart:], "@")
+	if i < 0 {
+		return spec, ""
+	}
+	i += pathStart
+	return spec[:i], spec[i+1:]
+}
+
+// parseSCPLike parses scp-style SSH locations like [user@]host:path
+func parseSCPLike(location string) (user, host, path string, ok bool) {
+	if isLocalPath(location) {
+		return "", "", "", false
+	}
+
+	hostPart, path, found := strings.Cut(location, ":")
+	if !found {
+		return "", "", "", false
+	}
+	// A slash before the colon means this is a path, not a host
+	if strings.Contains(hostPart, "/") {
+		return "", "", "", false
+	}
+
+	user, host, found = strings.Cut(hostPart, "@")
+	if !found {
+		host = user
+		user = ""
+	}
+	// Single letter hosts are Windows drive letters
+	if len(host) <= 1 || path == "" {
+		return "", "", "", false
+	}
+
+	return user, host, path, true
+}
+
+// isLocalPath checks if the given location is a local filesystem path
+func isLocalPath(location string) bool {
+	if location == "" {
+		return false
+	}
+	return filepath.IsAbs(location) ||
+		filepath.VolumeName(location) != "" ||
+		location[0] == '.' ||
+		location[0] == '/' ||
+		location[0] == '~'
+}
+
+// isGitURL checks if the given location refers to a Git repository instead of a local directory
+func isGitURL(location string) bool {
+	if location == "" {
+		return false
+	}
+	if strings.Contains(location, "://") {
+		return true
+	}
+	return !isLocalPath(location)
+}
@@ -0,0 +1,166 @@
+package registry_test
+
+import (
+	"testing"
+
+	"github.com/networkteam/shry/registry"
+)
+
+func TestGitURL(t *testing.T) {
+	tests := []struct {
+		name        string
+		location    string
+		expected    string
+		expectError bool
+	}{
+		{
+			name:     "host and path",
+			location: "github.com/networkteam/neos-components",
+			expected: "https://github.com/networkteam/neos-components",
+		},
+		{
+			name:     "https URL",
+			location: "https://github.com/networkteam/neos-components.git",
+			expected: "https://github.com/networkteam/neos-components.git",
+		},
+		{
+			name:     "plain http URL",
+			location: "http://git.internal:8080/registry.git",
+			expected: "http://git.internal:8080/registry.git",
+		},
+		{
+			name:     "ssh URL",
+			location: "ssh://git@gitlab.company:2222/team/registry.git",
+			expected: "ssh://git@gitlab.company:2222/team/registry.git",
+		},
+		{
+			name:     "scp-style location",
+			location: "git@gitlab.company:team/registry.git",
+			expected: "ssh://git@gitlab.company/team/registry.git",
+		},
+		{
+			name:     "scp-style location without user",
+			location: "gitlab.company:team/registry.git",
+			expected: "ssh://gitlab.company/team/registry.git",
+		},
+		{
+			name:     "file URL",
+			location: "file:///srv/git/registry.git",
+			expected: "file:///srv/git/registry.git",
+		},
+		{
+			name:        "unsupported scheme",
+			location:    "ftp://example.com/registry.git",
+			expectError: true,
+		},
+		{
+			name:        "URL without host",
+			location:    "https:///registry.git",
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := registry.GitURL(tt.location)
+			if tt.expectError {
+				if err == nil {
+					t.Error("GitURL() expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("GitURL() unexpected error: %v", err)
+				return
+			}
+			if got != tt.expected {
+				t.Errorf("GitURL() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestSplitRef(t *testing.T) {
+	tests := []struct {
+		name             string
+		spec             string
+		expectedLocation string
+		expectedRef      string
+	}{
+		{
+			name:             "host and path without ref",
+			spec:             "github.com/networkteam/neos-components",
+			expectedLocation: "github.com/networkteam/neos-components",
+		},
+		{
+			name:             "host and path with ref",
+			spec:             "github.com/networkteam/neos-components@v1.2.0",
+			expectedLocation: "github.com/networkteam/neos-components",
+			expectedRef:      "v1.2.0",
+		},
+		{
+			name:             "branch with slash",
+			spec:             "github.com/networkteam/neos-components@feature/button",
+			expectedLocation: "github.com/networkteam/neos-components",
+			expectedRef:      "feature/button",
+		},
+		{
+			name:             "scp-style without ref",
+			spec:             "git@gitlab.company:team/registry.git",
+			expectedLocation: "git@gitlab.company:team/registry.git",
+		},
+		{
+			name:             "scp-style with ref",
+			spec:             "git@gitlab.company:team/registry.git@main",
+			expectedLocation: "git@gitlab.company:team/registry.git",
+			expectedRef:      "main",
+		},
+		{
+			name:             "ssh URL with user and ref",
+			spec:             "ssh://git@gitlab.company/team/registry.git@main",
+			expectedLocation: "ssh://git@gitlab.company/team/registry.git",
+			expectedRef:      "main",
+		},
+		{
+			name:             "https URL with user and without ref",
+			spec:             "https://deploy@gitlab.company/team/registry.git",
+			expectedLocation: "https://deploy@gitlab.company/team/registry.git",
+		},
+		{
+			name:             "local path",
+			spec:             "../registry",
+			expectedLocation: "../registry",
+		},
+		{
+			name:             "relative local path with @",
+			spec:             "../packages/@acme/registry",
+			expectedLocation: "../packages/@acme/registry",
+		},
+		{
+			name:             "absolute local path with @",
+			spec:             "/srv/packages/@acme/registry",
+			expectedLocation: "/srv/packages/@acme/registry",
+		},
+		{
+			name:             "home local path with @",
+			spec:             "~/packages/@acme/registry",
+			expectedLocation: "~/packages/@acme/registry",
+		},
+		{
+			name: "empty",
+			spec: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			location, ref := registry.SplitRef(tt.spec)
+			if location != tt.expectedLocation {
+				t.Errorf("SplitRef() location = %v, want %v", location, tt.expectedLocation)
+			}
+			if ref != tt.expectedRef {
+				t.Errorf("SplitRef() ref = %v, want %v", ref, tt.expectedRef)
+			}
+		})
+	}
+}
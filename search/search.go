@@ -0,0 +1,140 @@
+package search
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/networkteam/shry/config"
+)
+
+// Field weights, a match in the name counts more than a match in the description
+const (
+	nameWeight        = 5
+	titleWeight       = 4
+	tagWeight         = 4
+	categoryWeight    = 2
+	descriptionWeight = 1
+)
+
+// Match is a component matching a query
+type Match struct {
+	Component *config.Component
+	Score     int
+}
+
+// Rank returns the components matching the query, best matches first.
+// Every word of the query must match at least one field of a component.
+func Rank(query string, components []*config.Component) []Match {
+	var matches []Match
+	for _, component := range components {
+		if score := Score(query, component); score > 0 {
+			matches = append(matches, Match{Component: component, Score: score})
+		}
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].Score != matches[j].Score {
+			return matches[i].Score > matches[j].Score
+		}
+		return matches[i].Component.Name < matches[j].Component.Name
+	})
+
+	return matches
+}
+
+// Score returns how well the component matches the query, 0 means no match
+func Score(query string, component *config.Component) int {
+	words := strings.Fields(strings.ToLower(query))
+	if len(words) == 0 {
+		return 0
+	}
+
+	var total int
+	for _, word := range words {
+		best := 0
+		best = max(best, nameWeight*matchScore(word, component.Name))
+		best = max(best, titleWeight*matchScore(word, component.Title))
+		best = max(best, categoryWeight*matchScore(word, component.Category))
+		best = max(best, descriptionWeight*matchScore(word, component.Description))
+		for _, tag := range component.Tags {
+			best = max(best, tagWeight*matchScore(word, tag))
+		}
+
+		if best == 0 {
+			return 0
+		}
+		total += best
+	}
+
+	return total
+}
+
+// matchScore scores a single (lower case) word against a text:
+// exact matches score highest, followed by prefixes, substrings at word boundaries, substrings and fuzzy subsequences.
+func matchScore(word, text string) int {
+	if text == "" {
+		return 0
+	}
+	text = strings.ToLower(text)
+
+	switch {
+	case text == word:
+		return 100
+	case strings.HasPrefix(text, word):
+		return 80
+	}
+
+	if idx := strings.Index(text, word); idx >= 0 {
+		if isWordBoundary(text, idx) {
+			return 70
+		}
+		return 50
+	}
+
+	return fuzzyScore(word, text)
+}
+
+// fuzzyScore scores the word as a subsequence of the text, rewarding consecutive characters and word boundaries.
+// The result is between 1 and 40, or 0 if the word is no subsequence of the text.
+func fuzzyScore(word, text string) int {
+	wordRunes := []rune(word)
+	textRunes := []rune(text)
+
+	score := 0
+	wi := 0
+	lastMatch := -1
+	for ti := 0; ti < len(textRunes) && wi < len(wordRunes); ti++ {
+		if textRunes[ti] != wordRunes[wi] {
+			continue
+		}
+
+		switch {
+		case lastMatch == ti-1:
+			score += 3
+		case isWordBoundary(text, len(string(textRunes[:ti]))):
+			score += 2
+		default:
+			score++
+		}
+		lastMatch = ti
+		wi++
+	}
+
+	if wi < len(wordRunes) {
+		return 0
+	}
+
+	// Normalize by the maximum score of a fully consecutive match
+	return max(1, score*40/(3*len(wordRunes)))
+}
+
+// isWordBoundary checks if the byte index is at the start of a word in the text
+func isWordBoundary(text string, idx int) bool {
+	if idx == 0 {
+		return true
+	}
+	prev, _ := utf8.DecodeLastRuneInString(text[:idx])
+	return !unicode.IsLetter(prev) && !unicode.IsDigit(prev)
+}
@@ -0,0 +1,77 @@
+package search_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/networkteam/shry/config"
+	"github.com/networkteam/shry/search"
+)
+
+func TestRank(t *testing.T) {
+	components := []*config.Component{
+		{Name: "button", Title: "Button", Description: "A clickable button", Category: "Atoms", Tags: []string{"form", "cta"}},
+		{Name: "image-card", Title: "Image Card", Description: "A card with an image and a button", Category: "Molecules"},
+		{Name: "card", Title: "Card", Category: "Molecules", Tags: []string{"teaser"}},
+		{Name: "newsletter-form", Title: "Newsletter Form", Description: "Subscribe to a newsletter", Category: "Organisms", Tags: []string{"form"}},
+	}
+
+	tests := []struct {
+		name     string
+		query    string
+		expected []string
+	}{
+		{
+			name:     "exact name before substring",
+			query:    "card",
+			expected: []string{"card", "image-card"},
+		},
+		{
+			name:     "name before description",
+			query:    "button",
+			expected: []string{"button", "image-card"},
+		},
+		{
+			name:     "tag",
+			query:    "teaser",
+			expected: []string{"card"},
+		},
+		{
+			name:     "equal scores sorted by name",
+			query:    "form",
+			expected: []string{"button", "newsletter-form"},
+		},
+		{
+			name:     "category",
+			query:    "molecules",
+			expected: []string{"card", "image-card"},
+		},
+		{
+			name:     "fuzzy subsequence",
+			query:    "imgcrd",
+			expected: []string{"image-card"},
+		},
+		{
+			name:     "all words must match",
+			query:    "card image",
+			expected: []string{"image-card"},
+		},
+		{
+			name:     "no match",
+			query:    "carousel",
+			expected: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var actual []string
+			for _, match := range search.Rank(tt.query, components) {
+				actual = append(actual, match.Component.Name)
+			}
+			if !reflect.DeepEqual(actual, tt.expected) {
+				t.Errorf("Rank(%q) = %v, want %v", tt.query, actual, tt.expected)
+			}
+		})
+	}
+}
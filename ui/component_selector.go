@@ -2,27 +2,42 @@ package ui
 
 import (
 	"fmt"
+	"slices"
 	"strings"
 
+	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/list"
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
 
 	"github.com/networkteam/shry/config"
+	"github.com/networkteam/shry/search"
+	"github.com/networkteam/shry/template"
 )
 
+// allCategories is the facet showing components of all categories
+const allCategories = "All"
+
 // ComponentItem represents a component in the selection list
 type ComponentItem struct {
 	name      string
 	component *config.Component
+	// selected is shared by all items of a selector
+	selected map[string]bool
 }
 
 func (i ComponentItem) FilterValue() string { return i.name }
 
 func (i ComponentItem) Title() string {
+	title := i.name
 	if i.component.Title != "" {
-		return i.component.Title
+		title = i.component.Title
+	}
+
+	if i.selected[i.name] {
+		return "[x] " + title
 	}
-	return i.name
+	return "[ ] " + title
 }
 
 func (i ComponentItem) Description() string {
@@ -38,13 +53,44 @@ func (i ComponentItem) Description() string {
 		parts = append(parts, fmt.Sprintf("Category: %s", i.component.Category))
 	}
 
+	// Add tags if available
+	if len(i.component.Tags) > 0 {
+		parts = append(parts, fmt.Sprintf("Tags: %s", strings.Join(i.component.Tags, ", ")))
+	}
+
 	return strings.Join(parts, " • ")
 }
 
+// selectorKeyMap are the keys of the selector in addition to the list keys
+type selectorKeyMap struct {
+	toggle       key.Binding
+	toggleAll    key.Binding
+	nextCategory key.Binding
+	prevCategory key.Binding
+	confirm      key.Binding
+}
+
+var selectorKeys = selectorKeyMap{
+	toggle:       key.NewBinding(key.WithKeys(" "), key.WithHelp("space", "select")),
+	toggleAll:    key.NewBinding(key.WithKeys("a"), key.WithHelp("a", "select all")),
+	nextCategory: key.NewBinding(key.WithKeys("tab"), key.WithHelp("tab", "next category")),
+	prevCategory: key.NewBinding(key.WithKeys("shift+tab"), key.WithHelp("shift+tab", "previous category")),
+	confirm:      key.NewBinding(key.WithKeys("enter"), key.WithHelp("enter", "add")),
+}
+
 // ComponentSelectorModel handles the component selection state
 type ComponentSelectorModel struct {
-	list     list.Model
-	choice   string
+	list       list.Model
+	components []*config.Component
+	variables  map[string]any
+	selected   map[string]bool
+
+	// categories are the facets, the first one shows all components
+	categories []string
+	category   int
+
+	width    int
+	choices  []string
 	quitting bool
 }
 
@@ -55,19 +101,51 @@ func (m ComponentSelectorModel) Init() tea.Cmd {
 func (m ComponentSelectorModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.WindowSizeMsg:
-		m.list.SetWidth(msg.Width)
+		m.width = msg.Width
+		m.list.SetSize(msg.Width/2, msg.Height-2)
 		return m, nil
 
 	case tea.KeyMsg:
-		switch keypress := msg.String(); keypress {
-		case "ctrl+c", "q", "esc":
+		// Keys are typed into the filter while filtering
+		if m.list.FilterState() == list.Filtering {
+			break
+		}
+
+		switch {
+		case msg.String() == "ctrl+c", msg.String() == "q",
+			msg.String() == "esc" && m.list.FilterState() == list.Unfiltered:
 			m.quitting = true
 			return m, tea.Quit
 
-		case "enter":
-			i, ok := m.list.SelectedItem().(ComponentItem)
-			if ok {
-				m.choice = string(i.name)
+		case key.Matches(msg, selectorKeys.toggle):
+			if item, ok := m.list.SelectedItem().(ComponentItem); ok {
+				m.selected[item.name] = !m.selected[item.name]
+			}
+			return m, nil
+
+		case key.Matches(msg, selectorKeys.toggleAll):
+			m.toggleAllVisible()
+			return m, nil
+
+		case key.Matches(msg, selectorKeys.nextCategory):
+			m.setCategory((m.category + 1) % len(m.categories))
+			return m, nil
+
+		case key.Matches(msg, selectorKeys.prevCategory):
+			m.setCategory((m.category + len(m.categories) - 1) % len(m.categories))
+			return m, nil
+
+		case key.Matches(msg, selectorKeys.confirm):
+			// Without a selection the highlighted component is added
+			for _, component := range m.components {
+				if m.selected[component.Name] {
+					m.choices = append(m.choices, component.Name)
+				}
+			}
+			if len(m.choices) == 0 {
+				if item, ok := m.list.SelectedItem().(ComponentItem); ok {
+					m.choices = []string{item.name}
+				}
 			}
 			return m, tea.Quit
 		}
@@ -78,55 +156,185 @@ func (m ComponentSelectorModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, cmd
 }
 
+// toggleAllVisible selects all visible components, or deselects them if all are selected
+func (m *ComponentSelectorModel) toggleAllVisible() {
+	items := m.list.VisibleItems()
+	allSelected := true
+	for _, item := range items {
+		if !m.selected[item.(ComponentItem).name] {
+			allSelected = false
+			break
+		}
+	}
+	for _, item := range items {
+		m.selected[item.(ComponentItem).name] = !allSelected
+	}
+}
+
+// setCategory shows only the components of the category with the given index
+func (m *ComponentSelectorModel) setCategory(index int) {
+	m.category = index
+
+	var facetComponents []*config.Component
+	var items []list.Item
+	for _, component := range m.components {
+		if index != 0 && component.Category != m.categories[index] {
+			continue
+		}
+		facetComponents = append(facetComponents, component)
+		items = append(items, ComponentItem{
+			name:      component.Name,
+			component: component,
+			selected:  m.selected,
+		})
+	}
+
+	m.list.Filter = ComponentFilter(facetComponents)
+	m.list.SetItems(items)
+	m.list.ResetSelected()
+	m.list.Title = fmt.Sprintf("Select components for platform: %s", m.components[0].Platform)
+	if index != 0 {
+		m.list.Title += fmt.Sprintf(" (%s)", m.categories[index])
+	}
+}
+
 func (m ComponentSelectorModel) View() string {
-	if m.choice != "" {
-		return NormalStyle.Margin(1, 0, 2, 4).Render(fmt.Sprintf("Selected component: %s", m.choice))
+	if len(m.choices) > 0 {
+		return NormalStyle.Margin(1, 0, 2, 4).Render(fmt.Sprintf("Selected components: %s", strings.Join(m.choices, ", ")))
 	}
 	if m.quitting {
 		return NormalStyle.Margin(1, 0, 2, 4).Render("No component selected.")
 	}
-	return "\n" + m.list.View()
+
+	var details string
+	if item, ok := m.list.SelectedItem().(ComponentItem); ok {
+		details = m.renderDetails(item.component)
+	}
+
+	listView := lipgloss.NewStyle().Width(m.list.Width()).Render(m.list.View())
+	return "\n" + lipgloss.JoinHorizontal(lipgloss.Top, listView, details)
+}
+
+// renderDetails renders the pane with files, dependencies and required variables of the component
+func (m ComponentSelectorModel) renderDetails(component *config.Component) string {
+	var sb strings.Builder
+
+	title := component.Name
+	if component.Title != "" {
+		title = component.Title
+	}
+	sb.WriteString(TitleStyle.Render(title) + "\n")
+
+	sb.WriteString("\n" + TitleStyle.Render("Files") + "\n")
+	for _, file := range component.Files {
+		fmt.Fprintf(&sb, "%s\n  %s\n", file.Src, HelpStyle.Render("→ "+file.Dst))
+	}
+
+	if len(component.Dependencies) > 0 {
+		sb.WriteString("\n" + TitleStyle.Render("Dependencies") + "\n")
+		for _, dependency := range component.Dependencies {
+			fmt.Fprintf(&sb, "%s\n", dependency)
+		}
+	}
+
+	if len(component.Variants) > 0 {
+		sb.WriteString("\n" + TitleStyle.Render("Variants") + "\n")
+		for _, name := range component.VariantNames() {
+			fmt.Fprintf(&sb, "%s\n", name)
+		}
+	}
+
+	// Variables in destination paths are required, the project or the component defaults must define them
+	var variables []string
+	for _, file := range component.Files {
+		for _, name := range template.FindVariables(file.Dst) {
+			if !slices.Contains(variables, name) {
+				variables = append(variables, name)
+			}
+		}
+	}
+	if len(variables) > 0 {
+		sb.WriteString("\n" + TitleStyle.Render("Required variables") + "\n")
+		for _, name := range variables {
+			_, defined := m.variables[name]
+			if _, hasDefault := component.Variables[name]; defined || hasDefault {
+				fmt.Fprintf(&sb, "%s %s\n", SuccessStyle.Render("✓"), name)
+			} else {
+				fmt.Fprintf(&sb, "%s %s %s\n", ErrorStyle.Render("✗"), name, HelpStyle.Render("(not defined)"))
+			}
+		}
+	}
+
+	paneWidth := max(20, m.width-m.list.Width()-4)
+	return BaseStyle.Width(paneWidth).Render(strings.TrimRight(sb.String(), "\n"))
+}
+
+// ComponentFilter returns a list filter ranking the components like `shry search`.
+// The components must be in the same order as the list items.
+func ComponentFilter(components []*config.Component) list.FilterFunc {
+	return func(term string, targets []string) []list.Rank {
+		var ranks []list.Rank
+		for _, match := range search.Rank(term, components) {
+			ranks = append(ranks, list.Rank{Index: slices.Index(components, match.Component)})
+		}
+		return ranks
+	}
 }
 
-// ShowComponentSelector displays an interactive component selection list
-func ShowComponentSelector(components map[string]map[string]*config.Component, platform string) (string, error) {
+// ShowComponentSelector displays an interactive component selection list with a detail pane.
+// Multiple components can be selected, variables are the project variables to check required variables.
+func ShowComponentSelector(components map[string]map[string]*config.Component, platform string, variables map[string]any) ([]string, error) {
 	platformComponents, exists := components[platform]
-	if !exists {
-		return "", fmt.Errorf("no components found for platform %s", platform)
+	if !exists || len(platformComponents) == 0 {
+		return nil, fmt.Errorf("no components found for platform %s", platform)
 	}
 
-	// Convert components to list items
-	items := make([]list.Item, 0, len(platformComponents))
-	for name, component := range platformComponents {
-		items = append(items, ComponentItem{
-			name:      name,
-			component: component,
-		})
+	sorted := SortComponents(platformComponents)
+
+	// Collect categories for the facet in the order of the components
+	categories := []string{allCategories}
+	for _, group := range GroupComponents(sorted) {
+		if group.Category != "" {
+			categories = append(categories, group.Category)
+		}
 	}
 
 	const defaultWidth = 80
 	const listHeight = 20
 
-	l := list.New(items, list.NewDefaultDelegate(), defaultWidth, listHeight)
-	l.Title = fmt.Sprintf("Select a component for platform: %s", platform)
+	l := list.New(nil, list.NewDefaultDelegate(), defaultWidth/2, listHeight)
 	l.SetShowStatusBar(false)
 	l.SetFilteringEnabled(true)
 	l.Styles.Title = TitleStyle
 	l.Styles.PaginationStyle = list.DefaultStyles().PaginationStyle.PaddingLeft(4)
 	l.Styles.HelpStyle = HelpStyle
+	l.AdditionalShortHelpKeys = func() []key.Binding {
+		return []key.Binding{selectorKeys.toggle, selectorKeys.nextCategory, selectorKeys.confirm}
+	}
+	l.AdditionalFullHelpKeys = func() []key.Binding {
+		return []key.Binding{selectorKeys.toggle, selectorKeys.toggleAll, selectorKeys.nextCategory, selectorKeys.prevCategory, selectorKeys.confirm}
+	}
 
-	m := ComponentSelectorModel{list: l}
+	m := ComponentSelectorModel{
+		list:       l,
+		components: sorted,
+		variables:  variables,
+		selected:   make(map[string]bool),
+		categories: categories,
+		width:      defaultWidth,
+	}
+	m.setCategory(0)
 
 	p := tea.NewProgram(m)
 	result, err := p.Run()
 	if err != nil {
-		return "", fmt.Errorf("running component selector: %w", err)
+		return nil, fmt.Errorf("running component selector: %w", err)
 	}
 
 	finalModel := result.(ComponentSelectorModel)
-	if finalModel.quitting && finalModel.choice == "" {
-		return "", nil // User cancelled
+	if finalModel.quitting {
+		return nil, nil // User cancelled
 	}
 
-	return finalModel.choice, nil
+	return finalModel.choices, nil
 }
@@ -0,0 +1,67 @@
+package ui
+
+import (
+	"sort"
+
+	"github.com/networkteam/shry/config"
+)
+
+// ComponentInfo holds information about a component for listings
+type ComponentInfo struct {
+	Platform    string   `json:"platform" yaml:"platform"`
+	Name        string   `json:"name" yaml:"name"`
+	Title       string   `json:"title,omitempty" yaml:"title,omitempty"`
+	Description string   `json:"description,omitempty" yaml:"description,omitempty"`
+	Category    string   `json:"category,omitempty" yaml:"category,omitempty"`
+	Tags        []string `json:"tags,omitempty" yaml:"tags,omitempty"`
+}
+
+// SortComponents returns the components sorted by category and name, uncategorized components come last
+func SortComponents(components map[string]*config.Component) []*config.Component {
+	sorted := make([]*config.Component, 0, len(components))
+	for _, component := range components {
+		sorted = append(sorted, component)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		a, b := sorted[i], sorted[j]
+		if a.Category != b.Category {
+			if a.Category == "" || b.Category == "" {
+				return b.Category == ""
+			}
+			return a.Category < b.Category
+		}
+		return a.Name < b.Name
+	})
+	return sorted
+}
+
+// ComponentGroup is a category with its components, the category of uncategorized components is empty
+type ComponentGroup struct {
+	Category   string
+	Components []*config.Component
+}
+
+// GroupComponents groups components sorted by SortComponents by their category, keeping the order
+func GroupComponents(sorted []*config.Component) []ComponentGroup {
+	var groups []ComponentGroup
+	for _, component := range sorted {
+		if len(groups) == 0 || groups[len(groups)-1].Category != component.Category {
+			groups = append(groups, ComponentGroup{Category: component.Category})
+		}
+		group := &groups[len(groups)-1]
+		group.Components = append(group.Components, component)
+	}
+	return groups
+}
+
+// NewComponentInfo returns the listing information of a component
+func NewComponentInfo(component *config.Component) ComponentInfo {
+	return ComponentInfo{
+		Platform:    component.Platform,
+		Name:        component.Name,
+		Title:       component.Title,
+		Description: component.Description,
+		Category:    component.Category,
+		Tags:        component.Tags,
+	}
+}
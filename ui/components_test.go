@@ -0,0 +1,130 @@
+package ui_test
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/networkteam/shry/config"
+	"github.com/networkteam/shry/ui"
+)
+
+func TestSortComponents(t *testing.T) {
+	tests := []struct {
+		name       string
+		components []*config.Component
+		expected   []string
+	}{
+		{
+			name: "by name without categories",
+			components: []*config.Component{
+				{Name: "card"},
+				{Name: "button"},
+				{Name: "accordion"},
+			},
+			expected: []string{"accordion", "button", "card"},
+		},
+		{
+			name: "by category and name",
+			components: []*config.Component{
+				{Name: "card", Category: "Layout"},
+				{Name: "input", Category: "Forms"},
+				{Name: "button", Category: "Forms"},
+				{Name: "grid", Category: "Layout"},
+			},
+			expected: []string{"button", "input", "card", "grid"},
+		},
+		{
+			name: "uncategorized components last",
+			components: []*config.Component{
+				{Name: "accordion"},
+				{Name: "card", Category: "Layout"},
+				{Name: "button", Category: "Forms"},
+				{Name: "alert"},
+			},
+			expected: []string{"button", "card", "accordion", "alert"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			components := make(map[string]*config.Component)
+			for _, component := range tt.components {
+				components[component.Name] = component
+			}
+
+			var names []string
+			for _, component := range ui.SortComponents(components) {
+				names = append(names, component.Name)
+			}
+			if !slices.Equal(names, tt.expected) {
+				t.Errorf("SortComponents() = %v, want %v", names, tt.expected)
+			}
+		})
+	}
+}
+
+func TestGroupComponents(t *testing.T) {
+	tests := []struct {
+		name       string
+		components []*config.Component
+		expected   map[string][]string
+		categories []string
+	}{
+		{
+			name:       "no components",
+			categories: nil,
+		},
+		{
+			name: "only uncategorized components",
+			components: []*config.Component{
+				{Name: "button"},
+				{Name: "card"},
+			},
+			expected:   map[string][]string{"": {"button", "card"}},
+			categories: []string{""},
+		},
+		{
+			name: "categories in order with uncategorized components last",
+			components: []*config.Component{
+				{Name: "alert"},
+				{Name: "grid", Category: "Layout"},
+				{Name: "input", Category: "Forms"},
+				{Name: "button", Category: "Forms"},
+				{Name: "card", Category: "Layout"},
+			},
+			expected: map[string][]string{
+				"Forms":  {"button", "input"},
+				"Layout": {"card", "grid"},
+				"":       {"alert"},
+			},
+			categories: []string{"Forms", "Layout", ""},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			components := make(map[string]*config.Component)
+			for _, component := range tt.components {
+				components[component.Name] = component
+			}
+
+			groups := ui.GroupComponents(ui.SortComponents(components))
+
+			var categories []string
+			for _, group := range groups {
+				categories = append(categories, group.Category)
+
+				var names []string
+				for _, component := range group.Components {
+					names = append(names, component.Name)
+				}
+				if !slices.Equal(names, tt.expected[group.Category]) {
+					t.Errorf("components of category %q = %v, want %v", group.Category, names, tt.expected[group.Category])
+				}
+			}
+			if !slices.Equal(categories, tt.categories) {
+				t.Errorf("GroupComponents() categories = %q, want %q", categories, tt.categories)
+			}
+		})
+	}
+}
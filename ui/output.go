@@ -0,0 +1,82 @@
+package ui
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+	"github.com/urfave/cli/v2"
+	"golang.org/x/term"
+	"gopkg.in/yaml.v3"
+)
+
+// OutputFormat is the format of command output
+type OutputFormat string
+
+const (
+	// OutputText is human readable output
+	OutputText OutputFormat = "text"
+	// OutputJSON is JSON output for scripts
+	OutputJSON OutputFormat = "json"
+	// OutputYAML is YAML output for scripts
+	OutputYAML OutputFormat = "yaml"
+)
+
+// stylingEnabled is false if styles must not be rendered (see ConfigureStyling)
+var stylingEnabled = true
+
+// OutputFlag returns the flag to choose the output format of a command
+func OutputFlag() *cli.StringFlag {
+	return &cli.StringFlag{
+		Name:    "output",
+		Aliases: []string{"o"},
+		Usage:   "Output format (text, json, yaml)",
+		Value:   string(OutputText),
+		EnvVars: []string{"SHRY_OUTPUT"},
+	}
+}
+
+// GetOutputFormat returns the validated output format of the command
+func GetOutputFormat(c *cli.Context) (OutputFormat, error) {
+	format := OutputFormat(c.String("output"))
+	switch format {
+	case OutputText, OutputJSON, OutputYAML:
+		return format, nil
+	}
+	return "", fmt.Errorf("unsupported output format %s, expected text, json or yaml", format)
+}
+
+// WriteStructured writes the value as JSON or YAML
+func WriteStructured(w io.Writer, format OutputFormat, v any) error {
+	switch format {
+	case OutputJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(v)
+	case OutputYAML:
+		encoder := yaml.NewEncoder(w)
+		encoder.SetIndent(2)
+		if err := encoder.Encode(v); err != nil {
+			return err
+		}
+		return encoder.Close()
+	}
+	return fmt.Errorf("output format %s is not structured", format)
+}
+
+// ConfigureStyling disables colors and styles if stdout is not a terminal or NO_COLOR is set.
+// It must be called before any output is rendered.
+func ConfigureStyling() {
+	if os.Getenv("NO_COLOR") != "" || !term.IsTerminal(int(os.Stdout.Fd())) {
+		stylingEnabled = false
+		lipgloss.SetColorProfile(termenv.Ascii)
+	}
+}
+
+// StylingEnabled returns true if output can be styled
+func StylingEnabled() bool {
+	return stylingEnabled
+}
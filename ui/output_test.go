@@ -0,0 +1,59 @@
+package ui_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/networkteam/shry/config"
+	"github.com/networkteam/shry/ui"
+)
+
+func TestWriteStructured(t *testing.T) {
+	components := []ui.ComponentInfo{
+		ui.NewComponentInfo(&config.Component{
+			Platform:    "neos",
+			Name:        "button",
+			Title:       "Button",
+			Description: "A button with variants",
+			Category:    "Forms",
+			Tags:        []string{"action", "form"},
+		}),
+		ui.NewComponentInfo(&config.Component{
+			Platform: "neos",
+			Name:     "card",
+		}),
+	}
+
+	tests := []struct {
+		format ui.OutputFormat
+		golden string
+	}{
+		{format: ui.OutputJSON, golden: "components.json"},
+		{format: ui.OutputYAML, golden: "components.yaml"},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.format), func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := ui.WriteStructured(&buf, tt.format, components); err != nil {
+				t.Fatalf("WriteStructured() unexpected error: %v", err)
+			}
+
+			expected, err := os.ReadFile(filepath.Join("testdata", tt.golden))
+			if err != nil {
+				t.Fatalf("reading golden file: %v", err)
+			}
+			if buf.String() != string(expected) {
+				t.Errorf("WriteStructured() =\n%s\nwant\n%s", buf.String(), expected)
+			}
+		})
+	}
+
+	t.Run("text is not structured", func(t *testing.T) {
+		if err := ui.WriteStructured(&bytes.Buffer{}, ui.OutputText, components); err == nil {
+			t.Error("WriteStructured() expected error, got nil")
+		}
+	})
+}
@@ -3,6 +3,7 @@ package ui
 import (
 	"fmt"
 	"os"
+	"slices"
 	"sort"
 	"strconv"
 
@@ -14,10 +15,12 @@ import (
 
 // RegistryInfo holds information about a registry for table display
 type RegistryInfo struct {
-	Location   string
-	Status     string
-	Platforms  int
-	Components int
+	Location    string `json:"location" yaml:"location"`
+	Name        string `json:"name,omitempty" yaml:"name,omitempty"`
+	Description string `json:"description,omitempty" yaml:"description,omitempty"`
+	Status      string `json:"status" yaml:"status"`
+	Platforms   int    `json:"platforms" yaml:"platforms"`
+	Components  int    `json:"components" yaml:"components"`
 }
 
 // CollectRegistryTableInfo gathers registry information from the global configuration
@@ -60,6 +63,20 @@ func CollectRegistryTableInfo(c *cli.Context, globalConfig *config.GlobalConfig)
 					info.Components += len(platformComponents)
 				}
 			}
+
+			// Platforms of the manifest without components count as well
+			manifest, err := reg.Manifest()
+			if err != nil {
+				info.Status = "Error"
+			} else if manifest != nil {
+				info.Name = manifest.Name
+				info.Description = manifest.Description
+				for _, platform := range manifest.PlatformNames() {
+					if _, exists := components[platform]; !exists {
+						info.Platforms++
+					}
+				}
+			}
 		}
 
 		registries = append(registries, info)
@@ -91,9 +108,22 @@ func FormatRegistryTable(registries []RegistryInfo, options TableOptions) string
 		maxRegistryWidth = 50
 	}
 
+	// The name column is only shown if a registry has a manifest with a name
+	maxNameWidth := 0
+	for _, reg := range registries {
+		maxNameWidth = max(maxNameWidth, len(reg.Name))
+	}
+	if maxNameWidth > 0 {
+		maxNameWidth = min(max(maxNameWidth, len("Name")), 30)
+	}
+
 	// Define headers and column widths
 	headers := []string{"Registry", "Status", "Platforms", "Components"}
 	columnWidths := []int{maxRegistryWidth, 10, 10, 12}
+	if maxNameWidth > 0 {
+		headers = slices.Insert(headers, 1, "Name")
+		columnWidths = slices.Insert(columnWidths, 1, maxNameWidth)
+	}
 
 	// Convert registry data to table rows
 	var rows [][]string
@@ -107,6 +137,9 @@ func FormatRegistryTable(registries []RegistryInfo, options TableOptions) string
 			strconv.Itoa(reg.Platforms),
 			strconv.Itoa(reg.Components),
 		}
+		if maxNameWidth > 0 {
+			row = slices.Insert(row, 1, TruncateText(reg.Name, maxNameWidth))
+		}
 		rows = append(rows, row)
 	}
 